@@ -0,0 +1,72 @@
+// Package builder creates CommerceML import and offers documents
+// checking required fields and generating stable Ид when absent
+//
+//	x, err := builder.NewImport().
+//		Owner(xml.Владелец{Наименование: "Торговый дом"}).
+//		Classifier("", "Основной").
+//		Group("", "Одежда", "").
+//		Product(xml.Товар{Артикул: "ПЛ-001", Наименование: "Платье"}, "Одежда").
+//		Build()
+package builder
+
+import (
+	"crypto/sha1"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sevkin/go-cml/xml"
+)
+
+const (
+	// Version is ВерсияСхемы of built documents by default
+	Version = "2.05"
+
+	dateFormat = "2006-01-02T15:04:05"
+)
+
+// ID returns stable Ид in UUID form (name based, version 5) for parts,
+// so the same source data gets the same Ид on every export
+func ID(parts ...string) string {
+	sum := sha1.Sum([]byte(strings.Join(parts, "\x00")))
+	sum[6] = (sum[6] & 0x0f) | 0x50
+	sum[8] = (sum[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", sum[0:4], sum[4:6], sum[6:8], sum[8:10], sum[10:16])
+}
+
+// document holds fields common for import and offers builders
+type document struct {
+	version string
+	date    time.Time
+	owner   *xml.Владелец
+	err     error
+}
+
+func newDocument() document {
+	return document{version: Version, date: time.Now()}
+}
+
+// fail keeps the first error, further calls are no-op
+func (d *document) fail(format string, args ...interface{}) {
+	if d.err == nil {
+		d.err = fmt.Errorf(format, args...)
+	}
+}
+
+func (d *document) setOwner(o xml.Владелец) {
+	if len(o.Наименование) == 0 {
+		d.fail("owner: Наименование is required")
+		return
+	}
+	if len(o.Ид) == 0 {
+		o.Ид = ID("owner", o.ИНН, o.Наименование)
+	}
+	d.owner = &o
+}
+
+func (d *document) root() *xml.КоммерческаяИнформация {
+	return &xml.КоммерческаяИнформация{
+		ВерсияСхемы:      d.version,
+		ДатаФормирования: d.date.Format(dateFormat),
+	}
+}
@@ -0,0 +1,142 @@
+package builder
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sevkin/go-cml/xml"
+	"github.com/sevkin/go-cml/xml/xsd"
+)
+
+var owner = xml.Владелец{Наименование: "Торговый дом", ИНН: "7701234567"}
+
+func importDoc() *Import {
+	return NewImport().
+		Date(time.Date(2019, 7, 1, 10, 15, 42, 0, time.UTC)).
+		Owner(owner).
+		Classifier("", "Основной").
+		Group("", "Одежда", "").
+		Group("", "Платья", "Одежда").
+		Group("g2", "Обувь", "").
+		Product(xml.Товар{Артикул: "ПЛ-001", Наименование: "Платье летнее"}, "Одежда>Платья").
+		Product(xml.Товар{Наименование: "Кеды", Группы: []string{"g2"}})
+}
+
+func TestImport(t *testing.T) {
+	x, err := importDoc().Build()
+	assert.Nil(t, err)
+	assert.Nil(t, xsd.ValidateDocument(x))
+
+	assert.Equal(t, "2019-07-01T10:15:42", x.ДатаФормирования)
+	assert.Equal(t, Version, x.ВерсияСхемы)
+	assert.Equal(t, ID("owner", "7701234567", "Торговый дом"), x.Классификатор.Владелец.Ид)
+
+	groups := x.Классификатор.Группы
+	assert.Equal(t, 2, len(groups))
+	assert.Equal(t, ID("group", "Одежда"), groups[0].Ид)
+	dresses := (*groups[0].Группы)[0]
+	assert.Equal(t, ID("group", "Одежда>Платья"), dresses.Ид)
+	assert.Nil(t, groups[1].Группы)
+
+	assert.Equal(t, "Основной", x.Каталог.Наименование)
+	assert.Equal(t, x.Классификатор.Ид, x.Каталог.ИдКлассификатора)
+	products := x.Каталог.Товары
+	assert.Equal(t, ID("product", "ПЛ-001"), products[0].Ид)
+	assert.Equal(t, []string{dresses.Ид}, products[0].Группы)
+	assert.Equal(t, []string{"g2"}, products[1].Группы)
+
+	// Ид are stable between builds
+	y, err := importDoc().Build()
+	assert.Nil(t, err)
+	assert.Equal(t, x, y)
+}
+
+func TestImportCatalogAfterProducts(t *testing.T) {
+	x, err := NewImport().
+		Owner(owner).
+		Classifier("", "Основной").
+		Product(xml.Товар{Артикул: "ПЛ-001", Наименование: "Платье летнее"}).
+		Catalog("", "Каталог").
+		Build()
+	assert.Nil(t, err)
+	assert.Equal(t, ID("catalog", "Каталог"), x.Каталог.Ид)
+	assert.Equal(t, "Каталог", x.Каталог.Наименование)
+	assert.Equal(t, 1, len(x.Каталог.Товары))
+}
+
+func TestImportErrors(t *testing.T) {
+	for name, b := range map[string]*Import{
+		"no classifier": NewImport().Owner(owner),
+		"no owner":      NewImport().Classifier("", "Основной"),
+		"no group name": importDoc().Group("", "", ""),
+		"no parent":     importDoc().Group("", "Сумки", "Аксессуары"),
+		"dup group":     importDoc().Group("g2", "Обувь", ""),
+		"dup path":      importDoc().Group("g3", "Обувь", ""),
+		"no name":       importDoc().Product(xml.Товар{Артикул: "A"}),
+		"no group":      importDoc().Product(xml.Товар{Наименование: "Сумка"}, "Сумки"),
+		"no group id":   importDoc().Product(xml.Товар{Наименование: "Сумка", Группы: []string{"g9"}}),
+		"dup product":   importDoc().Product(xml.Товар{Артикул: "ПЛ-001", Наименование: "Платье"}),
+	} {
+		_, err := b.Build()
+		assert.NotNil(t, err, name)
+	}
+}
+
+func offersDoc() *Offers {
+	return NewOffers().
+		Owner(owner).
+		Package("", "Пакет предложений", "cat", "cls").
+		PriceType("retail", "Розничная", "RUB").
+		Tax("Розничная", "НДС", true).
+		Warehouse("", "Основной").
+		Offer(xml.Предложение{Ид: "p1", Наименование: "Платье летнее",
+			Цены:       []xml.Цена{{ИдТипаЦены: "retail", ЦенаЗаЕдиницу: "2500"}},
+			Количество: "3",
+			Склад:      []xml.Остаток{{ИдСклада: ID("warehouse", "Основной"), КоличествоНаСкладе: "3"}},
+		})
+}
+
+func TestOffers(t *testing.T) {
+	x, err := offersDoc().Changes().Build()
+	assert.Nil(t, err)
+	assert.Nil(t, xsd.ValidateDocument(x))
+
+	pack := x.ПакетПредложений
+	assert.True(t, pack.СодержитТолькоИзменения)
+	assert.Equal(t, ID("offers", "Пакет предложений"), pack.Ид)
+	assert.Equal(t, "cat", pack.ИдКаталога)
+	assert.Equal(t, "НДС", pack.ТипыЦен[0].Налог.Наименование)
+	assert.True(t, pack.ТипыЦен[0].Налог.УчтеноВСумме)
+	assert.Equal(t, 1, len(pack.Предложения))
+}
+
+func TestOffersErrors(t *testing.T) {
+	for name, b := range map[string]*Offers{
+		"no package":      NewOffers().Owner(owner),
+		"no catalog":      NewOffers().Owner(owner).Package("", "Пакет", "", ""),
+		"no tax type":     offersDoc().Tax("wholesale", "НДС", false),
+		"dup type":        offersDoc().PriceType("retail", "Розничная", "RUB"),
+		"no offer id":     offersDoc().Offer(xml.Предложение{Наименование: "Кеды"}),
+		"dup offer":       offersDoc().Offer(xml.Предложение{Ид: "p1", Наименование: "Платье"}),
+		"no price type":   offersDoc().Offer(xml.Предложение{Ид: "p2", Наименование: "Кеды", Цены: []xml.Цена{{ИдТипаЦены: "wholesale", ЦенаЗаЕдиницу: "1"}}}),
+		"no price":        offersDoc().Offer(xml.Предложение{Ид: "p2", Наименование: "Кеды", Цены: []xml.Цена{{ИдТипаЦены: "retail"}}}),
+		"no warehouse":    offersDoc().Offer(xml.Предложение{Ид: "p2", Наименование: "Кеды", Склад: []xml.Остаток{{ИдСклада: "w9", КоличествоНаСкладе: "1"}}}),
+		"no stock":        offersDoc().Offer(xml.Предложение{Ид: "p2", Наименование: "Кеды", Склад: []xml.Остаток{{ИдСклада: ID("warehouse", "Основной")}}}),
+		"no owner name":   offersDoc().Owner(xml.Владелец{}),
+		"no type name":    offersDoc().PriceType("x", "", ""),
+		"no store name":   offersDoc().Warehouse("x", ""),
+		"no offer name":   offersDoc().Offer(xml.Предложение{Ид: "p3"}),
+		"no package name": NewOffers().Owner(owner).Package("", "", "cat", ""),
+	} {
+		_, err := b.Build()
+		assert.NotNil(t, err, name)
+	}
+}
+
+func TestID(t *testing.T) {
+	assert.Equal(t, ID("a", "b"), ID("a", "b"))
+	assert.NotEqual(t, ID("a", "b"), ID("ab"))
+	assert.Regexp(t, "^[0-9a-f]{8}-[0-9a-f]{4}-5[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$", ID("x"))
+}
@@ -0,0 +1,227 @@
+package builder
+
+import (
+	"time"
+
+	"github.com/sevkin/go-cml/xml"
+)
+
+type (
+	// Import builds import.xml with Классификатор and Каталог
+	Import struct {
+		document
+		classifier *xml.Классификатор
+		catalog    *xml.Каталог
+		groups     map[string]*group
+		names      map[string]string // group path -> Ид
+		roots      []*group
+		products   map[string]bool
+	}
+
+	group struct {
+		xml.Группа
+		path     string
+		children []*group
+	}
+)
+
+// NewImport returns builder of import document
+func NewImport() *Import {
+	return &Import{
+		document: newDocument(),
+		groups:   make(map[string]*group),
+		names:    make(map[string]string),
+		products: make(map[string]bool),
+	}
+}
+
+// Version sets ВерсияСхемы
+func (b *Import) Version(v string) *Import {
+	b.version = v
+	return b
+}
+
+// Date sets ДатаФормирования
+func (b *Import) Date(t time.Time) *Import {
+	b.date = t
+	return b
+}
+
+// Owner sets Владелец of classifier and catalog. Ид is generated if empty
+func (b *Import) Owner(o xml.Владелец) *Import {
+	b.setOwner(o)
+	return b
+}
+
+// Classifier sets Классификатор. id is generated from name if empty
+func (b *Import) Classifier(id, name string) *Import {
+	if len(name) == 0 {
+		b.fail("classifier: Наименование is required")
+		return b
+	}
+	if len(id) == 0 {
+		id = ID("classifier", name)
+	}
+	b.classifier = &xml.Классификатор{Ид: id, Наименование: name}
+	return b
+}
+
+// Catalog sets Каталог. By default it is named after classifier
+func (b *Import) Catalog(id, name string) *Import {
+	if len(name) == 0 {
+		b.fail("catalog: Наименование is required")
+		return b
+	}
+	if len(id) == 0 {
+		id = ID("catalog", name)
+	}
+	if b.catalog == nil {
+		b.catalog = &xml.Каталог{}
+	}
+	b.catalog.Ид, b.catalog.Наименование = id, name
+	return b
+}
+
+// Group adds group to parent (Ид or path "A>B" of previously added group,
+// empty for top level). id is generated from path if empty
+func (b *Import) Group(id, name, parent string) *Import {
+	if len(name) == 0 {
+		b.fail("group %s: Наименование is required", id)
+		return b
+	}
+
+	var p *group
+	path := name
+	if len(parent) > 0 {
+		if p = b.group(parent); p == nil {
+			b.fail("group %s: parent %s not found", name, parent)
+			return b
+		}
+		path = p.path + ">" + name
+	}
+	if len(id) == 0 {
+		id = ID("group", path)
+	}
+	if _, found := b.groups[id]; found {
+		b.fail("group %s: duplicate Ид %s", name, id)
+		return b
+	}
+	// path refers to group, so it must be unique as well as Ид
+	if _, found := b.names[path]; found {
+		b.fail("group %s: duplicate path %s", id, path)
+		return b
+	}
+
+	g := &group{Группа: xml.Группа{Ид: id, Наименование: name}, path: path}
+	b.groups[id] = g
+	b.names[path] = id
+	if p != nil {
+		p.children = append(p.children, g)
+	} else {
+		b.roots = append(b.roots, g)
+	}
+	return b
+}
+
+// group finds group by Ид or path
+func (b *Import) group(ref string) *group {
+	if g, found := b.groups[ref]; found {
+		return g
+	}
+	if id, found := b.names[ref]; found {
+		return b.groups[id]
+	}
+	return nil
+}
+
+// Product adds product to groups (Ид or path of added groups).
+// Ид is generated from Артикул or Наименование and groups if empty
+func (b *Import) Product(p xml.Товар, groups ...string) *Import {
+	if len(p.Наименование) == 0 {
+		b.fail("product %s%s: Наименование is required", p.Ид, p.Артикул)
+		return b
+	}
+
+	ids := append([]string{}, p.Группы...)
+	for _, ref := range groups {
+		g := b.group(ref)
+		if g == nil {
+			b.fail("product %s: group %s not found", p.Наименование, ref)
+			return b
+		}
+		ids = append(ids, g.Ид)
+	}
+	for _, id := range p.Группы {
+		if _, found := b.groups[id]; !found {
+			b.fail("product %s: group %s not found", p.Наименование, id)
+			return b
+		}
+	}
+	p.Группы = ids
+
+	if len(p.Ид) == 0 {
+		p.Ид = ProductID(p)
+	}
+	if b.products[p.Ид] {
+		b.fail("product %s: duplicate Ид %s", p.Наименование, p.Ид)
+		return b
+	}
+	b.products[p.Ид] = true
+
+	if b.catalog == nil {
+		b.catalog = &xml.Каталог{}
+	}
+	b.catalog.Товары = append(b.catalog.Товары, p)
+	return b
+}
+
+// ProductID returns stable Ид of product generated from Артикул
+// or from Наименование and Группы if there is no Артикул
+func ProductID(p xml.Товар) string {
+	if len(p.Артикул) > 0 {
+		return ID("product", p.Артикул)
+	}
+	return ID(append([]string{"product", p.Наименование}, p.Группы...)...)
+}
+
+// Build returns document or the first error occurred
+func (b *Import) Build() (*xml.КоммерческаяИнформация, error) {
+	if b.err == nil && b.classifier == nil {
+		b.fail("classifier is not set")
+	}
+	if b.err == nil && b.owner == nil {
+		b.fail("owner is not set")
+	}
+	if b.err != nil {
+		return nil, b.err
+	}
+
+	x := b.root()
+
+	cls := *b.classifier
+	cls.Владелец = *b.owner
+	cls.Группы = buildGroups(b.roots)
+	x.Классификатор = &cls
+
+	if b.catalog != nil {
+		cat := *b.catalog
+		if len(cat.Наименование) == 0 {
+			cat.Ид, cat.Наименование = ID("catalog", cls.Наименование), cls.Наименование
+		}
+		cat.ИдКлассификатора = cls.Ид
+		x.Каталог = &cat
+	}
+	return x, nil
+}
+
+func buildGroups(groups []*group) []xml.Группа {
+	res := make([]xml.Группа, len(groups))
+	for idx, g := range groups {
+		res[idx] = g.Группа
+		if len(g.children) > 0 {
+			sub := buildGroups(g.children)
+			res[idx].Группы = &sub
+		}
+	}
+	return res
+}
@@ -0,0 +1,178 @@
+package builder
+
+import (
+	"time"
+
+	"github.com/sevkin/go-cml/xml"
+)
+
+// Offers builds offers.xml with ПакетПредложений
+type Offers struct {
+	document
+	pack       xml.ПакетПредложений
+	types      map[string]bool
+	warehouses map[string]bool
+	offers     map[string]bool
+}
+
+// NewOffers returns builder of offers document
+func NewOffers() *Offers {
+	return &Offers{
+		document:   newDocument(),
+		types:      make(map[string]bool),
+		warehouses: make(map[string]bool),
+		offers:     make(map[string]bool),
+	}
+}
+
+// Version sets ВерсияСхемы
+func (b *Offers) Version(v string) *Offers {
+	b.version = v
+	return b
+}
+
+// Date sets ДатаФормирования
+func (b *Offers) Date(t time.Time) *Offers {
+	b.date = t
+	return b
+}
+
+// Owner sets Владелец of offers package. Ид is generated if empty
+func (b *Offers) Owner(o xml.Владелец) *Offers {
+	b.setOwner(o)
+	return b
+}
+
+// Package sets Ид and Наименование of ПакетПредложений and Ид of catalog and
+// classifier of import document. id is generated from name if empty
+func (b *Offers) Package(id, name, catalogID, classifierID string) *Offers {
+	if len(name) == 0 {
+		b.fail("offers: Наименование is required")
+		return b
+	}
+	if len(catalogID) == 0 {
+		b.fail("offers: ИдКаталога is required")
+		return b
+	}
+	if len(id) == 0 {
+		id = ID("offers", name)
+	}
+	b.pack.Ид, b.pack.Наименование = id, name
+	b.pack.ИдКаталога, b.pack.ИдКлассификатора = catalogID, classifierID
+	return b
+}
+
+// Changes marks package as СодержитТолькоИзменения
+func (b *Offers) Changes() *Offers {
+	b.pack.СодержитТолькоИзменения = true
+	return b
+}
+
+// PriceType adds ТипЦены. id is generated from name if empty
+func (b *Offers) PriceType(id, name, currency string) *Offers {
+	if len(name) == 0 {
+		b.fail("price type %s: Наименование is required", id)
+		return b
+	}
+	if len(id) == 0 {
+		id = ID("price", name)
+	}
+	if b.types[id] {
+		b.fail("price type %s: duplicate Ид %s", name, id)
+		return b
+	}
+	b.types[id] = true
+
+	t := xml.ТипЦены{Ид: id, Наименование: name, Валюта: currency}
+	b.pack.ТипыЦен = append(b.pack.ТипыЦен, t)
+	return b
+}
+
+// Tax sets Налог (НДС) of price type. included means tax is in the price
+func (b *Offers) Tax(typeID, name string, included bool) *Offers {
+	for idx := range b.pack.ТипыЦен {
+		if t := &b.pack.ТипыЦен[idx]; t.Ид == typeID || t.Наименование == typeID {
+			t.Налог.Наименование, t.Налог.УчтеноВСумме = name, included
+			return b
+		}
+	}
+	b.fail("tax %s: price type %s not found", name, typeID)
+	return b
+}
+
+// Warehouse adds Склад. id is generated from name if empty
+func (b *Offers) Warehouse(id, name string) *Offers {
+	if len(name) == 0 {
+		b.fail("warehouse %s: Наименование is required", id)
+		return b
+	}
+	if len(id) == 0 {
+		id = ID("warehouse", name)
+	}
+	if b.warehouses[id] {
+		b.fail("warehouse %s: duplicate Ид %s", name, id)
+		return b
+	}
+	b.warehouses[id] = true
+	b.pack.Склады = append(b.pack.Склады, xml.Склад{Ид: id, Наименование: name})
+	return b
+}
+
+// Offer adds Предложение. Ид of product (or "product#variant") is required,
+// prices and stocks must refer to added price types and warehouses
+func (b *Offers) Offer(o xml.Предложение) *Offers {
+	if len(o.Ид) == 0 {
+		b.fail("offer %s: Ид is required", o.Наименование)
+		return b
+	}
+	if len(o.Наименование) == 0 {
+		b.fail("offer %s: Наименование is required", o.Ид)
+		return b
+	}
+	if b.offers[o.Ид] {
+		b.fail("offer %s: duplicate Ид", o.Ид)
+		return b
+	}
+	for _, p := range o.Цены {
+		if !b.types[p.ИдТипаЦены] {
+			b.fail("offer %s: price type %s not found", o.Ид, p.ИдТипаЦены)
+			return b
+		}
+		if len(p.ЦенаЗаЕдиницу) == 0 {
+			b.fail("offer %s: ЦенаЗаЕдиницу is required", o.Ид)
+			return b
+		}
+	}
+	for _, s := range o.Склад {
+		if !b.warehouses[s.ИдСклада] {
+			b.fail("offer %s: warehouse %s not found", o.Ид, s.ИдСклада)
+			return b
+		}
+		if len(s.КоличествоНаСкладе) == 0 {
+			b.fail("offer %s: КоличествоНаСкладе is required", o.Ид)
+			return b
+		}
+	}
+	b.offers[o.Ид] = true
+	b.pack.Предложения = append(b.pack.Предложения, o)
+	return b
+}
+
+// Build returns document or the first error occurred
+func (b *Offers) Build() (*xml.КоммерческаяИнформация, error) {
+	if b.err == nil && len(b.pack.Ид) == 0 {
+		b.fail("offers package is not set")
+	}
+	if b.err == nil && b.owner == nil {
+		b.fail("owner is not set")
+	}
+	if b.err != nil {
+		return nil, b.err
+	}
+
+	x := b.root()
+	pack := b.pack
+	pack.Владелец = *b.owner
+	x.ПакетПредложений = &pack
+	return x, nil
+}
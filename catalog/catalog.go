@@ -0,0 +1,514 @@
+// Package catalog indexes CommerceML import and offers documents in memory:
+// groups tree with paths, products by Ид and Артикул, offers joined to products,
+// price types and warehouses
+package catalog
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/sevkin/go-cml/xml"
+)
+
+type (
+	// Catalog is the index of one or more CommerceML documents
+	Catalog struct {
+		rows       []xml.GroupRow // merged classifiers in tree order
+		groups     map[string]*Group
+		roots      []*Group
+		products   map[string]*Product
+		productIDs []string
+		articles   map[string][]*Product
+		offers     map[string]*Offer
+		offerIDs   []string
+		priceTypes map[string]*xml.ТипЦены
+		typeIDs    []string
+		warehouses map[string]*xml.Склад
+		storeIDs   []string
+		properties map[string]*xml.Свойство
+		propIDs    []string
+	}
+
+	// Group of products with links to parent and subgroups
+	Group struct {
+		*xml.Группа
+		Parent   *Group
+		Children []*Group
+		Path     []string // names from the top level group
+		Products []*Product
+	}
+
+	// Product with its groups and offers including variants
+	Product struct {
+		*xml.Товар
+		Groups  []*Group
+		Offers  []*Offer
+		Missing []string // Ид of referenced groups not found in classifier
+	}
+
+	// Offer of product or its variant with prices and stock
+	Offer struct {
+		*xml.Предложение
+		Product *Product
+		partial bool // known from ИзменениеПакетаПредложений only
+	}
+)
+
+// New returns empty Catalog
+func New() *Catalog {
+	return &Catalog{
+		groups:     make(map[string]*Group),
+		products:   make(map[string]*Product),
+		articles:   make(map[string][]*Product),
+		offers:     make(map[string]*Offer),
+		priceTypes: make(map[string]*xml.ТипЦены),
+		warehouses: make(map[string]*xml.Склад),
+		properties: make(map[string]*xml.Свойство),
+	}
+}
+
+// Load returns Catalog of documents (import, offers...)
+func Load(docs ...*xml.КоммерческаяИнформация) *Catalog {
+	c := New()
+	for _, x := range docs {
+		c.Add(x)
+	}
+	return c
+}
+
+// LoadFiles reads documents and returns their Catalog
+func LoadFiles(fnames ...string) (*Catalog, error) {
+	c := New()
+	for _, fname := range fnames {
+		x, err := xml.ReadFile(fname)
+		if err != nil {
+			return nil, err
+		}
+		c.Add(x)
+	}
+	return c, nil
+}
+
+// Add indexes document. Elements with the same Ид replace previous ones.
+// Document must not be modified after adding
+func (c *Catalog) Add(x *xml.КоммерческаяИнформация) {
+	if x == nil {
+		return
+	}
+	if x.Классификатор != nil {
+		c.addGroups(x.Классификатор)
+		props := x.Классификатор.Properties()
+		for idx := range props {
+			p := &props[idx]
+			if _, found := c.properties[p.Ид]; !found {
+				c.propIDs = append(c.propIDs, p.Ид)
+			}
+			c.properties[p.Ид] = p
+		}
+	}
+	if x.Каталог != nil {
+		for idx := range x.Каталог.Товары {
+			p := &x.Каталог.Товары[idx]
+			if _, found := c.products[p.Ид]; !found {
+				c.productIDs = append(c.productIDs, p.Ид)
+			}
+			c.products[p.Ид] = &Product{Товар: p}
+		}
+	}
+	if x.ПакетПредложений != nil {
+		c.addOffers(x.ПакетПредложений)
+	}
+	if x.ИзменениеПакетаПредложений != nil {
+		c.addChanges(x.ИзменениеПакетаПредложений)
+	}
+	c.link()
+}
+
+// addGroups upserts groups of classifier by Ид and rebuilds groups tree.
+// Top level groups keep their parents known before
+func (c *Catalog) addGroups(k *xml.Классификатор) {
+	index := make(map[string]int, len(c.rows))
+	for idx, row := range c.rows {
+		index[row.Ид] = idx
+	}
+	for _, row := range k.Flatten() {
+		idx, found := index[row.Ид]
+		if !found {
+			index[row.Ид] = len(c.rows)
+			c.rows = append(c.rows, row)
+			continue
+		}
+		if len(row.ParentИд) == 0 {
+			row.ParentИд = c.rows[idx].ParentИд
+		}
+		c.rows[idx] = row
+	}
+
+	tree := xml.BuildGroups(c.rows)
+	c.groups = make(map[string]*Group, len(c.rows))
+	c.roots = c.indexGroups(tree, nil)
+}
+
+// indexGroups indexes groups of tree and returns them
+func (c *Catalog) indexGroups(groups []xml.Группа, parent *Group) []*Group {
+	res := make([]*Group, 0, len(groups))
+	for idx := range groups {
+		g := &Group{
+			Группа: &groups[idx],
+			Parent: parent,
+		}
+		if parent != nil {
+			g.Path = append(append([]string{}, parent.Path...), g.Наименование)
+		} else {
+			g.Path = []string{g.Наименование}
+		}
+		c.groups[g.Ид] = g
+		if g.Группы != nil {
+			g.Children = c.indexGroups(*g.Группы, g)
+		}
+		res = append(res, g)
+	}
+	return res
+}
+
+func (c *Catalog) addOffers(pack *xml.ПакетПредложений) {
+	for idx := range pack.ТипыЦен {
+		t := &pack.ТипыЦен[idx]
+		if _, found := c.priceTypes[t.Ид]; !found {
+			c.typeIDs = append(c.typeIDs, t.Ид)
+		}
+		c.priceTypes[t.Ид] = t
+	}
+	for idx := range pack.Склады {
+		s := &pack.Склады[idx]
+		if _, found := c.warehouses[s.Ид]; !found {
+			c.storeIDs = append(c.storeIDs, s.Ид)
+		}
+		c.warehouses[s.Ид] = s
+	}
+	for idx := range pack.Предложения {
+		o := &pack.Предложения[idx]
+		old, found := c.offers[o.Ид]
+		if !found {
+			c.offerIDs = append(c.offerIDs, o.Ид)
+		}
+		if found && old.partial {
+			// 3.x offers.xml goes without prices and stock loaded before
+			merged := *o
+			if len(merged.Цены) == 0 {
+				merged.Цены = old.Цены
+			}
+			if len(merged.Склад) == 0 {
+				merged.Склад = old.Склад
+			}
+			if len(merged.Количество) == 0 {
+				merged.Количество = old.Количество
+			}
+			o = &merged
+		}
+		c.offers[o.Ид] = &Offer{Предложение: o}
+	}
+}
+
+// addChanges applies prices and stock of CommerceML 3.x to offers.
+// Price types and warehouses not declared yet are registered with Ид only,
+// offers.xml loaded later replaces them
+func (c *Catalog) addChanges(pack *xml.ИзменениеПакетаПредложений) {
+	for idx := range pack.Предложения {
+		change := &pack.Предложения[idx]
+		for _, p := range change.Prices() {
+			if _, found := c.priceTypes[p.ИдТипаЦены]; !found {
+				c.typeIDs = append(c.typeIDs, p.ИдТипаЦены)
+				c.priceTypes[p.ИдТипаЦены] = &xml.ТипЦены{Ид: p.ИдТипаЦены, Валюта: p.Валюта}
+			}
+		}
+		for _, r := range change.Rests() {
+			if r.Склад == nil {
+				continue
+			}
+			if _, found := c.warehouses[r.Склад.Ид]; !found {
+				c.storeIDs = append(c.storeIDs, r.Склад.Ид)
+				c.warehouses[r.Склад.Ид] = &xml.Склад{Ид: r.Склад.Ид}
+			}
+		}
+		old, found := c.offers[change.Ид]
+		if !found {
+			c.offerIDs = append(c.offerIDs, change.Ид)
+			o := change.Offer()
+			c.offers[change.Ид] = &Offer{Предложение: &o, partial: true}
+			continue
+		}
+		o := *old.Предложение
+		change.Apply(&o)
+		c.offers[change.Ид] = &Offer{Предложение: &o, partial: old.partial}
+	}
+}
+
+// link rebuilds references between groups, products and offers
+func (c *Catalog) link() {
+	for _, g := range c.groups {
+		g.Products = nil
+	}
+	c.articles = make(map[string][]*Product)
+
+	for _, id := range c.productIDs {
+		p := c.products[id]
+		p.Groups, p.Offers, p.Missing = nil, nil, nil
+		for _, gid := range p.Товар.Группы {
+			if g, found := c.groups[gid]; found {
+				p.Groups = append(p.Groups, g)
+				g.Products = append(g.Products, p)
+			} else {
+				p.Missing = append(p.Missing, gid)
+			}
+		}
+		if len(p.Артикул) > 0 {
+			c.articles[p.Артикул] = append(c.articles[p.Артикул], p)
+		}
+	}
+
+	for _, id := range c.offerIDs {
+		o := c.offers[id]
+		o.Product = c.products[o.ProductID()]
+		if o.Product != nil {
+			o.Product.Offers = append(o.Product.Offers, o)
+		}
+	}
+}
+
+// Group returns group by Ид or nil
+func (c *Catalog) Group(id string) *Group {
+	return c.groups[id]
+}
+
+// Groups returns top level groups
+func (c *Catalog) Groups() []*Group {
+	return c.roots
+}
+
+// AllGroups returns all groups in depth-first order
+func (c *Catalog) AllGroups() []*Group {
+	var groups []*Group
+	var walk func([]*Group)
+	walk = func(gs []*Group) {
+		for _, g := range gs {
+			groups = append(groups, g)
+			walk(g.Children)
+		}
+	}
+	walk(c.roots)
+	return groups
+}
+
+// Product returns product by Ид or nil
+func (c *Catalog) Product(id string) *Product {
+	return c.products[id]
+}
+
+// ProductsByArticle returns products having Артикул
+func (c *Catalog) ProductsByArticle(article string) []*Product {
+	return c.articles[article]
+}
+
+// Products returns all products in order of documents
+func (c *Catalog) Products() []*Product {
+	products := make([]*Product, len(c.productIDs))
+	for idx, id := range c.productIDs {
+		products[idx] = c.products[id]
+	}
+	return products
+}
+
+// Orphans returns products not linked to any group of classifier
+func (c *Catalog) Orphans() []*Product {
+	var products []*Product
+	for _, id := range c.productIDs {
+		if p := c.products[id]; len(p.Groups) == 0 {
+			products = append(products, p)
+		}
+	}
+	return products
+}
+
+// Offer returns offer by Ид or nil
+func (c *Catalog) Offer(id string) *Offer {
+	return c.offers[id]
+}
+
+// Offers returns all offers in order of documents
+func (c *Catalog) Offers() []*Offer {
+	offers := make([]*Offer, len(c.offerIDs))
+	for idx, id := range c.offerIDs {
+		offers[idx] = c.offers[id]
+	}
+	return offers
+}
+
+// Unmatched returns offers of products not found in catalog
+func (c *Catalog) Unmatched() []*Offer {
+	var offers []*Offer
+	for _, id := range c.offerIDs {
+		if o := c.offers[id]; o.Product == nil {
+			offers = append(offers, o)
+		}
+	}
+	return offers
+}
+
+// PriceType returns ТипЦены by Ид or nil
+func (c *Catalog) PriceType(id string) *xml.ТипЦены {
+	return c.priceTypes[id]
+}
+
+// PriceTypes returns all ТипЦены in order of documents
+func (c *Catalog) PriceTypes() []*xml.ТипЦены {
+	types := make([]*xml.ТипЦены, len(c.typeIDs))
+	for idx, id := range c.typeIDs {
+		types[idx] = c.priceTypes[id]
+	}
+	return types
+}
+
+// FindPriceType returns ТипЦены by Ид or Наименование or nil
+func (c *Catalog) FindPriceType(ref string) *xml.ТипЦены {
+	if t, found := c.priceTypes[ref]; found {
+		return t
+	}
+	for _, id := range c.typeIDs {
+		if t := c.priceTypes[id]; t.Наименование == ref {
+			return t
+		}
+	}
+	return nil
+}
+
+// Warehouse returns Склад by Ид or nil
+func (c *Catalog) Warehouse(id string) *xml.Склад {
+	return c.warehouses[id]
+}
+
+// Warehouses returns all Склад in order of documents
+func (c *Catalog) Warehouses() []*xml.Склад {
+	stores := make([]*xml.Склад, len(c.storeIDs))
+	for idx, id := range c.storeIDs {
+		stores[idx] = c.warehouses[id]
+	}
+	return stores
+}
+
+// FindWarehouse returns Склад by Ид or Наименование or nil
+func (c *Catalog) FindWarehouse(ref string) *xml.Склад {
+	if s, found := c.warehouses[ref]; found {
+		return s
+	}
+	for _, id := range c.storeIDs {
+		if s := c.warehouses[id]; s.Наименование == ref {
+			return s
+		}
+	}
+	return nil
+}
+
+// Property returns Свойство by Ид or nil
+func (c *Catalog) Property(id string) *xml.Свойство {
+	return c.properties[id]
+}
+
+// Properties returns all Свойство in order of documents
+func (c *Catalog) Properties() []*xml.Свойство {
+	props := make([]*xml.Свойство, len(c.propIDs))
+	for idx, id := range c.propIDs {
+		props[idx] = c.properties[id]
+	}
+	return props
+}
+
+// FindProperty returns Свойство by Ид or Наименование or nil
+func (c *Catalog) FindProperty(ref string) *xml.Свойство {
+	if p, found := c.properties[ref]; found {
+		return p
+	}
+	for _, id := range c.propIDs {
+		if p := c.properties[id]; p.Наименование == ref {
+			return p
+		}
+	}
+	return nil
+}
+
+// AllProducts returns products of group and all its subgroups
+func (g *Group) AllProducts() []*Product {
+	var products []*Product
+	seen := make(map[*Product]bool)
+	var walk func(*Group)
+	walk = func(g *Group) {
+		for _, p := range g.Products {
+			if !seen[p] {
+				seen[p] = true
+				products = append(products, p)
+			}
+		}
+		for _, sub := range g.Children {
+			walk(sub)
+		}
+	}
+	walk(g)
+	return products
+}
+
+// Variant reports whether offer is for product characteristic
+func (o *Offer) Variant() bool {
+	return len(o.VariantID()) > 0
+}
+
+// Price returns price of offer by ИдТипаЦены or nil
+func (o *Offer) Price(typeID string) *xml.Цена {
+	for idx := range o.Цены {
+		if o.Цены[idx].ИдТипаЦены == typeID {
+			return &o.Цены[idx]
+		}
+	}
+	return nil
+}
+
+// Stock returns КоличествоНаСкладе of offer by ИдСклада
+func (o *Offer) Stock(warehouseID string) (string, bool) {
+	for _, s := range o.Склад {
+		if s.ИдСклада == warehouseID {
+			return s.КоличествоНаСкладе, true
+		}
+	}
+	return "", false
+}
+
+// Quantity returns sum of stock on given warehouses. Without warehouses
+// it returns Количество or sum of stock on all warehouses if Количество is empty
+// (offers of 3.x rests carry stock by warehouses only).
+// Values which are not numbers count as zero
+func (o *Offer) Quantity(warehouseIDs ...string) float64 {
+	if len(warehouseIDs) == 0 {
+		if len(strings.TrimSpace(o.Количество)) > 0 {
+			return number(o.Количество)
+		}
+		var sum float64
+		for _, rest := range o.Склад {
+			sum += number(rest.КоличествоНаСкладе)
+		}
+		return sum
+	}
+	var sum float64
+	for _, id := range warehouseIDs {
+		if v, found := o.Stock(id); found {
+			sum += number(v)
+		}
+	}
+	return sum
+}
+
+func number(s string) float64 {
+	v, err := strconv.ParseFloat(strings.Replace(strings.TrimSpace(s), ",", ".", 1), 64)
+	if err != nil {
+		return 0
+	}
+	return v
+}
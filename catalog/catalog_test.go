@@ -0,0 +1,259 @@
+package catalog
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sevkin/go-cml/xml"
+)
+
+func documents() (*xml.КоммерческаяИнформация, *xml.КоммерческаяИнформация) {
+	imp := &xml.КоммерческаяИнформация{
+		Классификатор: &xml.Классификатор{
+			Группы: []xml.Группа{
+				{Ид: "g1", Наименование: "Одежда", Группы: &[]xml.Группа{
+					{Ид: "g11", Наименование: "Мужская", Группы: &[]xml.Группа{
+						{Ид: "g111", Наименование: "Брюки"},
+					}},
+				}},
+				{Ид: "g2", Наименование: "Обувь"},
+			},
+			Свойства: &[]xml.Свойство{{Ид: "color", Наименование: "Цвет", ВариантыЗначений: []xml.ВариантЗначения{
+				{ИдЗначения: "blue", Значение: "Синий"}}}},
+		},
+		Каталог: &xml.Каталог{
+			Товары: []xml.Товар{
+				{Ид: "p1", Артикул: "A-1", Наименование: "Брюки", Группы: []string{"g111"},
+					ЗначенияСвойств: &[]xml.ЗначенияСвойства{{Ид: "color", Значение: "blue"}}},
+				{Ид: "p2", Артикул: "A-2", Наименование: "Рубашка", Группы: []string{"g11"}},
+				{Ид: "p3", Артикул: "A-1", Наименование: "Кеды", Группы: []string{"g2"}},
+			},
+		},
+	}
+	offers := &xml.КоммерческаяИнформация{
+		ПакетПредложений: &xml.ПакетПредложений{
+			ТипыЦен: []xml.ТипЦены{{Ид: "retail", Наименование: "Розничная", Валюта: "RUB"}},
+			Склады:  []xml.Склад{{Ид: "w1", Наименование: "Основной"}},
+			Предложения: []xml.Предложение{
+				{Ид: "p1", Количество: "3",
+					Цены:  []xml.Цена{{ИдТипаЦены: "retail", ЦенаЗаЕдиницу: "100"}},
+					Склад: []xml.Остаток{{ИдСклада: "w1", КоличествоНаСкладе: "3"}}},
+				{Ид: "p3", Количество: "0"},
+				{Ид: "p2#v1", Количество: "1", ХарактеристикиТовара: &[]xml.ХарактеристикаТовара{
+					{Наименование: "Размер", Значение: "48"}}},
+				{Ид: "p2#v2", Количество: "2", ХарактеристикиТовара: &[]xml.ХарактеристикаТовара{
+					{Наименование: "Размер", Значение: "50"}}},
+				{Ид: "p9", Количество: "1"},
+			},
+		},
+	}
+	return imp, offers
+}
+
+func TestLoad(t *testing.T) {
+	c := Load(documents())
+
+	assert.Equal(t, 2, len(c.Groups()))
+	assert.Equal(t, 4, len(c.AllGroups()))
+	assert.Equal(t, 3, len(c.Products()))
+	assert.Equal(t, 5, len(c.Offers()))
+
+	g := c.Group("g111")
+	assert.Equal(t, []string{"Одежда", "Мужская", "Брюки"}, g.Path)
+	assert.Equal(t, "g11", g.Parent.Ид)
+	assert.Equal(t, "g1", g.Parent.Parent.Ид)
+	assert.Nil(t, g.Parent.Parent.Parent)
+	assert.Equal(t, 1, len(g.Products))
+
+	all := c.Group("g1").AllProducts()
+	assert.Equal(t, 2, len(all))
+	assert.Equal(t, "p2", all[0].Ид)
+	assert.Equal(t, "p1", all[1].Ид)
+
+	assert.Equal(t, 2, len(c.ProductsByArticle("A-1")))
+	assert.Nil(t, c.ProductsByArticle("none"))
+
+	p := c.Product("p1")
+	assert.Equal(t, "g111", p.Groups[0].Ид)
+	assert.Equal(t, 1, len(p.Offers))
+	assert.Equal(t, p, p.Offers[0].Product)
+	assert.Nil(t, c.Offer("p9").Product)
+	assert.False(t, p.Offers[0].Variant())
+
+	variants := c.Product("p2").Offers
+	assert.Equal(t, 2, len(variants))
+	assert.True(t, variants[0].Variant())
+	assert.Equal(t, "p2#v1", variants[0].Ид)
+	assert.Equal(t, "p2#v2", variants[1].Ид)
+	assert.Equal(t, "p2", variants[1].Product.Ид)
+
+	o := c.Offer("p1")
+	assert.Equal(t, "100", o.Price("retail").ЦенаЗаЕдиницу)
+	assert.Nil(t, o.Price("wholesale"))
+	stock, found := o.Stock("w1")
+	assert.True(t, found)
+	assert.Equal(t, "3", stock)
+	_, found = o.Stock("w2")
+	assert.False(t, found)
+
+	assert.Equal(t, "RUB", c.PriceType("retail").Валюта)
+	assert.Equal(t, 1, len(c.PriceTypes()))
+	assert.Equal(t, "Основной", c.Warehouse("w1").Наименование)
+	assert.Equal(t, 1, len(c.Warehouses()))
+
+	assert.Equal(t, "retail", c.FindPriceType("Розничная").Ид)
+	assert.Equal(t, "retail", c.FindPriceType("retail").Ид)
+	assert.Nil(t, c.FindPriceType("Оптовая"))
+	assert.Equal(t, "w1", c.FindWarehouse("Основной").Ид)
+	assert.Nil(t, c.FindWarehouse("w2"))
+	assert.Equal(t, "color", c.FindProperty("Цвет").Ид)
+	assert.Nil(t, c.FindProperty("Размер"))
+
+	assert.Equal(t, 1, len(c.Properties()))
+	color, _ := p.Property("color")
+	assert.Equal(t, "Синий", c.Property("color").Value(color))
+	assert.Nil(t, c.Property("size"))
+}
+
+func TestLoadOffersFirst(t *testing.T) {
+	imp, offers := documents()
+	c := Load(offers, imp)
+	assert.Equal(t, 1, len(c.Product("p1").Offers))
+}
+
+func TestLoadChanges(t *testing.T) {
+	c, err := LoadFiles("../xml/testdata/import.xml", "../xml/testdata/offers.xml",
+		"../xml/testdata/prices.xml", "../xml/testdata/rests.xml")
+	assert.Nil(t, err)
+	assert.Equal(t, 4, len(c.Offers()))
+
+	o := c.Offer("c5e4a1b1-3d2e-11e9-80d4-0cc47a7c2f11")
+	assert.Equal(t, "Брюки льняные", o.Наименование)
+	assert.Equal(t, "3300", o.Price("d1c0a2b3-4e5f-11e9-80d4-0cc47a7c2f11").ЦенаЗаЕдиницу)
+	assert.Equal(t, "2800", o.Price("d1c0a2b4-4e5f-11e9-80d4-0cc47a7c2f11").ЦенаЗаЕдиницу)
+	assert.Equal(t, 7.0, o.Quantity())
+	assert.Equal(t, 3.0, o.Quantity("e2f1b3c5-5d6e-11e9-80d4-0cc47a7c2f11"))
+	assert.Equal(t, o, o.Product.Offers[0])
+	assert.Equal(t, 0.0, c.Offer("c5e4a1b2-3d2e-11e9-80d4-0cc47a7c2f11").Quantity())
+
+	// documents are not modified
+	offers := xml.ReadMust("../xml/testdata/offers.xml")
+	c = Load(offers, xml.ReadMust("../xml/testdata/prices.xml"))
+	assert.Equal(t, "3450.50", offers.ПакетПредложений.Предложения[2].Цены[0].ЦенаЗаЕдиницу)
+}
+
+func TestLoadChangesFirst(t *testing.T) {
+	imp, offers := documents()
+	offers.ПакетПредложений.Предложения[0].Цены = nil
+	prices := &xml.КоммерческаяИнформация{ИзменениеПакетаПредложений: &xml.ИзменениеПакетаПредложений{
+		Предложения: []xml.ИзменениеПредложения{
+			{Ид: "p1", Цены: &[]xml.Цена{{ИдТипаЦены: "retail", ЦенаЗаЕдиницу: "150"}}},
+			{Ид: "unknown", Цены: &[]xml.Цена{{ИдТипаЦены: "retail", ЦенаЗаЕдиницу: "1"}}},
+		}}}
+
+	c := Load(imp, prices)
+	// price types of changes are known by Ид until offers are loaded
+	assert.Equal(t, []*xml.ТипЦены{{Ид: "retail"}}, c.PriceTypes())
+
+	c = Load(imp, prices, offers)
+	assert.Equal(t, len(offers.ПакетПредложений.ТипыЦен), len(c.PriceTypes()))
+	assert.Equal(t, "retail", c.PriceTypes()[0].Ид)
+	assert.NotEmpty(t, c.PriceTypes()[0].Наименование)
+	o := c.Offer("p1")
+	assert.Equal(t, "150", o.Price("retail").ЦенаЗаЕдиницу)
+	assert.Equal(t, "3", o.Количество)
+	assert.Nil(t, c.Offer("unknown").Product)
+
+	// later full offer replaces prices
+	c.Add(&xml.КоммерческаяИнформация{ПакетПредложений: &xml.ПакетПредложений{
+		Предложения: []xml.Предложение{{Ид: "p1", Цены: []xml.Цена{{ИдТипаЦены: "retail", ЦенаЗаЕдиницу: "200"}}}},
+	}})
+	assert.Equal(t, "200", c.Offer("p1").Price("retail").ЦенаЗаЕдиницу)
+}
+
+func TestLoadEmpty(t *testing.T) {
+	c := Load(&xml.КоммерческаяИнформация{}, nil)
+	assert.Equal(t, 0, len(c.AllGroups()))
+	assert.Equal(t, 0, len(c.Products()))
+	assert.Nil(t, c.Group("g1"))
+	assert.Nil(t, c.Orphans())
+	assert.Nil(t, c.Unmatched())
+}
+
+func TestOrphans(t *testing.T) {
+	imp, offers := documents()
+	imp.Каталог.Товары = append(imp.Каталог.Товары,
+		xml.Товар{Ид: "p4", Наименование: "Сумка", Группы: []string{"g9", "g2"}},
+		xml.Товар{Ид: "p5", Наименование: "Ремень", Группы: []string{"g9"}},
+		xml.Товар{Ид: "p6", Наименование: "Зонт"},
+	)
+	c := Load(imp, offers)
+
+	assert.Equal(t, []string{"g9"}, c.Product("p4").Missing)
+	assert.Equal(t, 1, len(c.Product("p4").Groups))
+	assert.Nil(t, c.Product("p1").Missing)
+
+	orphans := c.Orphans()
+	assert.Equal(t, 2, len(orphans))
+	assert.Equal(t, "p5", orphans[0].Ид)
+	assert.Equal(t, []string{"g9"}, orphans[0].Missing)
+	assert.Equal(t, "p6", orphans[1].Ид)
+
+	unmatched := c.Unmatched()
+	assert.Equal(t, 1, len(unmatched))
+	assert.Equal(t, "p9", unmatched[0].Ид)
+
+	// products only, without classifier
+	c = Load(&xml.КоммерческаяИнформация{Каталог: imp.Каталог})
+	assert.Equal(t, 6, len(c.Orphans()))
+}
+
+func TestAddReplacesGroup(t *testing.T) {
+	imp, _ := documents()
+	c := Load(imp)
+	c.Add(&xml.КоммерческаяИнформация{
+		Классификатор: &xml.Классификатор{
+			Группы: []xml.Группа{{Ид: "g2", Наименование: "Обувь и сумки"}},
+		},
+	})
+
+	assert.Equal(t, 2, len(c.Groups()))
+	assert.Equal(t, []string{"Обувь и сумки"}, c.Group("g2").Path)
+	assert.Equal(t, 1, len(c.Group("g2").Products))
+
+	// moved group brings its subgroups, top level group keeps its parent
+	c.Add(&xml.КоммерческаяИнформация{
+		Классификатор: &xml.Классификатор{
+			Группы: []xml.Группа{
+				{Ид: "g2", Наименование: "Обувь и сумки", Группы: &[]xml.Группа{
+					{Ид: "g11", Наименование: "Мужская"},
+				}},
+				{Ид: "g111", Наименование: "Брюки мужские"},
+			},
+		},
+	})
+	assert.Equal(t, 4, len(c.AllGroups()))
+	assert.Equal(t, 0, len(c.Group("g1").Children))
+	assert.Equal(t, c.Group("g2"), c.Group("g11").Parent)
+	assert.Equal(t, []string{"Обувь и сумки", "Мужская", "Брюки мужские"}, c.Group("g111").Path)
+	assert.Equal(t, c.Group("g111"), c.Product("p1").Groups[0])
+}
+
+func TestQuantity(t *testing.T) {
+	o := &Offer{Предложение: &xml.Предложение{Количество: "7,5", Склад: []xml.Остаток{
+		{ИдСклада: "w1", КоличествоНаСкладе: "5"},
+		{ИдСклада: "w2", КоличествоНаСкладе: "2.5"},
+		{ИдСклада: "w3", КоличествоНаСкладе: "n/a"},
+	}}}
+	assert.Equal(t, 7.5, o.Quantity())
+	assert.Equal(t, 5.0, o.Quantity("w1"))
+	assert.Equal(t, 7.5, o.Quantity("w1", "w2", "w3", "w4"))
+	assert.Equal(t, 0.0, (&Offer{Предложение: &xml.Предложение{}}).Quantity())
+
+	// stock by warehouses only
+	o.Количество = ""
+	assert.Equal(t, 7.5, o.Quantity())
+	o.Количество = "0"
+	assert.Equal(t, 0.0, o.Quantity())
+}
@@ -0,0 +1,60 @@
+package catalog
+
+import (
+	"fmt"
+
+	"github.com/sevkin/go-cml/currency"
+	"github.com/sevkin/go-cml/xml"
+)
+
+// Selection is price type and warehouses feeds are built of
+type Selection struct {
+	PriceType *xml.ТипЦены
+	// Warehouses are Ид of Склад to sum stock on, empty for Quantity of offer
+	Warehouses []string
+}
+
+// Select resolves price type and warehouses given by Ид or Наименование.
+// Empty priceType selects the first one
+func (c *Catalog) Select(priceType string, warehouses []string) (*Selection, error) {
+	s := &Selection{PriceType: c.FindPriceType(priceType)}
+	if len(priceType) == 0 {
+		if types := c.PriceTypes(); len(types) > 0 {
+			s.PriceType = types[0]
+		}
+	}
+	if s.PriceType == nil {
+		return nil, fmt.Errorf("unknown price type %q", priceType)
+	}
+
+	for _, ref := range warehouses {
+		w := c.FindWarehouse(ref)
+		if w == nil {
+			return nil, fmt.Errorf("unknown warehouse %q", ref)
+		}
+		s.Warehouses = append(s.Warehouses, w.Ид)
+	}
+	return s, nil
+}
+
+// Price returns Цена of offer by selected type, nil if it is missing or empty
+func (s *Selection) Price(o *Offer) *xml.Цена {
+	price := o.Price(s.PriceType.Ид)
+	if price == nil || len(price.ЦенаЗаЕдиницу) == 0 {
+		return nil
+	}
+	return price
+}
+
+// Currency returns ISO code of Валюта of price or of price type
+func (s *Selection) Currency(price *xml.Цена) string {
+	if price != nil && len(price.Валюта) > 0 {
+		return currency.Code(price.Валюта)
+	}
+	return currency.Code(s.PriceType.Валюта)
+}
+
+// Available reports whether offer is in stock on selected warehouses
+func (s *Selection) Available(o *Offer) bool {
+	return o.Quantity(s.Warehouses...) > 0
+}
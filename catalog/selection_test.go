@@ -0,0 +1,48 @@
+package catalog
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sevkin/go-cml/xml"
+)
+
+func TestSelect(t *testing.T) {
+	c, err := LoadFiles("../xml/testdata/import.xml", "../xml/testdata/offers.xml")
+	assert.Nil(t, err)
+
+	s, err := c.Select("", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "Розничная", s.PriceType.Наименование)
+	assert.Empty(t, s.Warehouses)
+
+	s, err = c.Select("Оптовая", []string{"Магазин на Ленина", "e2f1b3c4-5d6e-11e9-80d4-0cc47a7c2f11"})
+	assert.Nil(t, err)
+	assert.Equal(t, "d1c0a2b4-4e5f-11e9-80d4-0cc47a7c2f11", s.PriceType.Ид)
+	assert.Equal(t, []string{"e2f1b3c5-5d6e-11e9-80d4-0cc47a7c2f11", "e2f1b3c4-5d6e-11e9-80d4-0cc47a7c2f11"},
+		s.Warehouses)
+
+	_, err = c.Select("Закупочная", nil)
+	assert.NotNil(t, err)
+	_, err = c.Select("", []string{"Склад"})
+	assert.NotNil(t, err)
+	_, err = New().Select("", nil)
+	assert.NotNil(t, err)
+}
+
+func TestSelection(t *testing.T) {
+	s := &Selection{PriceType: &xml.ТипЦены{Ид: "retail", Валюта: "руб"}, Warehouses: []string{"w2"}}
+	o := &Offer{Предложение: &xml.Предложение{
+		Цены:  []xml.Цена{{ИдТипаЦены: "retail", ЦенаЗаЕдиницу: "10", Валюта: "USD"}, {ИдТипаЦены: "opt"}},
+		Склад: []xml.Остаток{{ИдСклада: "w1", КоличествоНаСкладе: "5"}},
+	}}
+	assert.Equal(t, "10", s.Price(o).ЦенаЗаЕдиницу)
+	assert.Equal(t, "USD", s.Currency(s.Price(o)))
+	assert.Equal(t, "RUB", s.Currency(nil))
+	assert.False(t, s.Available(o))
+
+	s.PriceType.Ид, s.Warehouses = "opt", nil
+	assert.Nil(t, s.Price(o))
+	assert.True(t, s.Available(o))
+}
@@ -0,0 +1,123 @@
+package client
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sevkin/go-cml/xml"
+)
+
+// Upload sends file dir/name to server as name (slash separated, relative to
+// exchange directory, e.g. import_files/ab/abcd.jpg) by pieces of limit bytes.
+// limit <= 0 means whole file at once
+func (c *Client) Upload(dir, name string, limit int64) error {
+	f, err := os.Open(filepath.Join(dir, filepath.FromSlash(name)))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	return c.upload(f, info.Size(), name, limit)
+}
+
+func (c *Client) upload(r io.Reader, size int64, name string, limit int64) error {
+	if limit <= 0 || limit > size {
+		limit = size
+	}
+	for {
+		n := limit
+		if n > size {
+			n = size
+		}
+		if err := c.File(r, n, name); err != nil {
+			return err
+		}
+		size -= n
+		if size <= 0 {
+			return nil
+		}
+	}
+}
+
+// UploadImport sends document fname (import.xml) with product images it references,
+// images keep paths relative to directory of document. Images go first,
+// so they exist when site imports the document. Packed files are sent
+// as one archive named after document (import.zip). Missing images are an error.
+// Site imports the document by Import(filepath.Base(fname)) afterwards
+func (c *Client) UploadImport(fname string, packed bool, limit int64) error {
+	x, err := xml.ReadFile(fname)
+	if err != nil {
+		return err
+	}
+	dir, base := filepath.Split(fname)
+	images, missing, err := xml.Images(x, dir)
+	if err != nil {
+		return err
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("upload: images not found: %s", strings.Join(missing, ", "))
+	}
+
+	if packed {
+		var buf bytes.Buffer
+		if err := Zip(&buf, dir, append(images, base)...); err != nil {
+			return err
+		}
+		name := strings.TrimSuffix(base, filepath.Ext(base)) + ".zip"
+		return c.upload(&buf, int64(buf.Len()), name, limit)
+	}
+
+	for _, name := range append(images, base) {
+		if err := c.Upload(dir, name, limit); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Zip packs files dir/names into zip archive written to w keeping names as is,
+// so server unpacks them to the same relative paths
+func Zip(w io.Writer, dir string, names ...string) error {
+	z := zip.NewWriter(w)
+	for _, name := range names {
+		if err := zipFile(z, dir, name); err != nil {
+			return err
+		}
+	}
+	return z.Close()
+}
+
+func zipFile(z *zip.Writer, dir, name string) error {
+	f, err := os.Open(filepath.Join(dir, filepath.FromSlash(name)))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	header, err := zip.FileInfoHeader(info)
+	if err != nil {
+		return err
+	}
+	header.Name = name
+	header.Method = zip.Deflate
+
+	zw, err := z.CreateHeader(header)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(zw, f)
+	return err
+}
@@ -0,0 +1,112 @@
+package client
+
+import (
+	"archive/zip"
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	gock "gopkg.in/h2non/gock.v1"
+)
+
+func exchangeDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "cml")
+	assert.Nil(t, err)
+	assert.Nil(t, os.MkdirAll(filepath.Join(dir, "import_files", "ab"), 0755))
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "import_files", "ab", "hw.jpg"), []byte("helloworld"), 0644))
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "import.xml"), []byte("<xml/>"), 0644))
+	return dir
+}
+
+func TestClientUpload(t *testing.T) {
+	dir := exchangeDir(t)
+	defer os.RemoveAll(dir)
+
+	client := New("http://localhost/1c.php", Catalog)
+
+	defer gock.Off()
+
+	for _, piece := range []string{"hell", "owor", "ld"} {
+		gock.New("http://localhost").
+			Post("/1c.php").
+			MatchParams(map[string]string{"mode": "file", "type": client._type,
+				"filename": "import_files/ab/hw.jpg"}).
+			BodyString(piece).
+			Reply(200).
+			BodyString("success")
+	}
+
+	assert.Nil(t, client.Upload(dir, "import_files/ab/hw.jpg", 4))
+	assert.True(t, gock.IsDone())
+
+	gock.New("http://localhost").
+		Post("/1c.php").
+		BodyString("helloworld").
+		Reply(200).
+		BodyString("success")
+
+	assert.Nil(t, client.Upload(dir, "import_files/ab/hw.jpg", 0))
+	assert.True(t, gock.IsDone())
+
+	assert.NotNil(t, client.Upload(dir, "import_files/ab/none.jpg", 0))
+}
+
+func TestZip(t *testing.T) {
+	dir := exchangeDir(t)
+	defer os.RemoveAll(dir)
+
+	buf := new(bytes.Buffer)
+	assert.Nil(t, Zip(buf, dir, "import.xml", "import_files/ab/hw.jpg"))
+
+	z, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(z.File))
+	assert.Equal(t, "import.xml", z.File[0].Name)
+	assert.Equal(t, "import_files/ab/hw.jpg", z.File[1].Name)
+
+	r, err := z.File[1].Open()
+	assert.Nil(t, err)
+	content, _ := ioutil.ReadAll(r)
+	assert.Equal(t, "helloworld", string(content))
+
+	assert.NotNil(t, Zip(new(bytes.Buffer), dir, "none.xml"))
+}
+
+func TestClientUploadImport(t *testing.T) {
+	dir := exchangeDir(t)
+	defer os.RemoveAll(dir)
+	doc := `<КоммерческаяИнформация ВерсияСхемы="2.05"><Каталог><Товары><Товар>` +
+		`<Ид>p1</Ид><Наименование>Кеды</Наименование>` +
+		`<Картинка>import_files/ab/hw.jpg</Картинка><Картинка> </Картинка>` +
+		`</Товар></Товары></Каталог></КоммерческаяИнформация>`
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "import.xml"), []byte(doc), 0644))
+
+	client := New("http://localhost/1c.php", Catalog)
+	defer gock.Off()
+
+	for _, name := range []string{"import_files/ab/hw.jpg", "import.xml"} {
+		gock.New("http://localhost").
+			Post("/1c.php").
+			MatchParams(map[string]string{"mode": "file", "filename": name}).
+			Reply(200).
+			BodyString("success")
+	}
+	assert.Nil(t, client.UploadImport(filepath.Join(dir, "import.xml"), false, 0))
+	assert.True(t, gock.IsDone())
+
+	gock.New("http://localhost").
+		Post("/1c.php").
+		MatchParams(map[string]string{"mode": "file", "filename": "import.zip"}).
+		Reply(200).
+		BodyString("success")
+	assert.Nil(t, client.UploadImport(filepath.Join(dir, "import.xml"), true, 0))
+	assert.True(t, gock.IsDone())
+
+	assert.Nil(t, os.Remove(filepath.Join(dir, "import_files", "ab", "hw.jpg")))
+	err := client.UploadImport(filepath.Join(dir, "import.xml"), false, 0)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "import_files/ab/hw.jpg")
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/sevkin/go-cml/catalog"
+	"github.com/sevkin/go-cml/xml"
+)
+
+// defaultColumns is used when CML2CSV_COLUMNS is not set
+const defaultColumns = "sku,path,name,quantity,prices,stocks"
+
+type (
+	// row of csv is an offer of product in group
+	row struct {
+		group   *catalog.Group
+		product *catalog.Product
+		offer   *catalog.Offer
+	}
+
+	column struct {
+		header string
+		value  func(r *row) string
+	}
+)
+
+// parseColumns parses comma separated column spec:
+//
+//	id, sku, path, name, quantity,
+//	price:<ТипЦены>, stock:<Склад>, requisite:<ЗначениеРеквизита>, property:<Свойство>,
+//	prices, stocks
+//
+// price types, warehouses and properties are referenced by Ид or Наименование.
+// prices and stocks expand to columns of every ТипЦены and Склад
+func parseColumns(spec string, data *catalog.Catalog) ([]column, error) {
+	var columns []column
+	for _, name := range strings.Split(spec, ",") {
+		name = strings.TrimSpace(name)
+		if len(name) == 0 {
+			continue
+		}
+		kind, arg := name, ""
+		if idx := strings.Index(name, ":"); idx >= 0 {
+			kind, arg = name[:idx], name[idx+1:]
+		}
+
+		switch kind {
+		case "prices":
+			for _, t := range data.PriceTypes() {
+				columns = append(columns, priceColumn(t))
+			}
+			continue
+		case "stocks":
+			for _, s := range data.Warehouses() {
+				columns = append(columns, stockColumn(s))
+			}
+			continue
+		}
+
+		c, err := newColumn(kind, arg, data)
+		if err != nil {
+			return nil, fmt.Errorf("column %s: %v", name, err)
+		}
+		columns = append(columns, c)
+	}
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("no columns")
+	}
+	return columns, nil
+}
+
+func newColumn(kind, arg string, data *catalog.Catalog) (column, error) {
+	switch kind {
+	case "id":
+		return column{"Ид", func(r *row) string { return r.offer.Ид }}, nil
+	case "sku":
+		return column{"Артикул", func(r *row) string {
+			if len(r.offer.Артикул) > 0 {
+				return r.offer.Артикул
+			}
+			return r.product.Артикул
+		}}, nil
+	case "path":
+		return column{"Группа", func(r *row) string { return strings.Join(r.group.Path, ">") }}, nil
+	case "name":
+		return column{"Наименование", func(r *row) string {
+			if r.offer.Variant() {
+				return fmt.Sprintf("%s (%s)", r.product.Наименование, r.offer.Characteristics())
+			}
+			return r.product.Наименование
+		}}, nil
+	case "quantity":
+		return column{"Количество", quantity}, nil
+	case "price":
+		t := data.FindPriceType(arg)
+		if t == nil {
+			return column{}, fmt.Errorf("price type not found")
+		}
+		return priceColumn(t), nil
+	case "stock":
+		s := data.FindWarehouse(arg)
+		if s == nil {
+			return column{}, fmt.Errorf("warehouse not found")
+		}
+		return stockColumn(s), nil
+	case "requisite":
+		return column{arg, func(r *row) string {
+			v, _ := r.product.Requisite(arg)
+			return v
+		}}, nil
+	case "property":
+		p := data.FindProperty(arg)
+		if p == nil {
+			return column{}, fmt.Errorf("property not found")
+		}
+		return column{p.Наименование, func(r *row) string {
+			v, found := r.product.Property(p.Ид)
+			if !found {
+				return ""
+			}
+			return p.Value(v)
+		}}, nil
+	}
+	return column{}, fmt.Errorf("unknown column")
+}
+
+// quantity is Количество of offer or its sum over warehouses (3.x rests),
+// empty if offer has no rests at all
+func quantity(r *row) string {
+	if len(strings.TrimSpace(r.offer.Количество)) == 0 && len(r.offer.Склад) == 0 {
+		return ""
+	}
+	return strconv.FormatFloat(r.offer.Quantity(), 'f', -1, 64)
+}
+
+// priceColumn is named "Наименование, Валюта" of price type,
+// Ид stands for Наименование of types known from 3.x prices only
+func priceColumn(t *xml.ТипЦены) column {
+	header := t.Наименование
+	if len(header) == 0 {
+		header = t.Ид
+	}
+	if len(t.Валюта) > 0 {
+		header += ", " + t.Валюта
+	}
+	return column{header, func(r *row) string {
+		if p := r.offer.Price(t.Ид); p != nil {
+			return p.ЦенаЗаЕдиницу
+		}
+		return ""
+	}}
+}
+
+// stockColumn is named by Наименование of warehouse or by its Ид
+func stockColumn(s *xml.Склад) column {
+	header := s.Наименование
+	if len(header) == 0 {
+		header = s.Ид
+	}
+	return column{header, func(r *row) string {
+		v, _ := r.offer.Stock(s.Ид)
+		return v
+	}}
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseColumns(t *testing.T) {
+	data := load(t)
+
+	for _, tc := range []struct {
+		spec    string
+		headers string
+	}{
+		{defaultColumns, "Артикул;Группа;Наименование;Количество;Розничная, RUB;Оптовая, RUB;Основной склад;Магазин на Ленина"},
+		{"id, name,,quantity", "Ид;Наименование;Количество"},
+		{"price:Оптовая,price:d1c0a2b3-4e5f-11e9-80d4-0cc47a7c2f11", "Оптовая, RUB;Розничная, RUB"},
+		{"stock:Магазин на Ленина", "Магазин на Ленина"},
+		{"requisite:ВидНоменклатуры,property:Цвет", "ВидНоменклатуры;Цвет"},
+	} {
+		columns, err := parseColumns(tc.spec, data)
+		assert.Nil(t, err, tc.spec)
+		var headers []string
+		for _, c := range columns {
+			headers = append(headers, c.header)
+		}
+		assert.Equal(t, tc.headers, strings.Join(headers, ";"), tc.spec)
+	}
+
+	for _, spec := range []string{
+		"",
+		" , ",
+		"sku,unknown",
+		"price:Закупочная",
+		"stock:Склад",
+		"property:Размер обуви",
+	} {
+		_, err := parseColumns(spec, data)
+		assert.NotNil(t, err, spec)
+	}
+}
+
+func TestColumnValues(t *testing.T) {
+	defer func(offerless bool) { conf.Offerless = offerless }(conf.Offerless)
+	conf.Offerless = true
+	data := load(t)
+	columns, err := parseColumns("sku,path,name,quantity,price:Розничная,stock:Основной склад,property:Цвет", data)
+	assert.Nil(t, err)
+
+	for _, tc := range []struct {
+		id, values string
+	}{
+		{"c5e4a1b0-3d2e-11e9-80d4-0cc47a7c2f11#8a9b0c1d-6e7f-11e9-80d4-0cc47a7c2f11",
+			"ПЛ-001;Одежда>Платья;Платье летнее (Размер: 44, Цвет: голубой);7;2990;5;Синий"},
+		// offerless product has empty offer columns
+		{"offerless", ";Обувь;Без предложений;;;;"},
+	} {
+		o := data.Offer(tc.id)
+		if o == nil {
+			p := data.Product(tc.id)
+			o = offers(p)[0]
+		}
+		r := &row{group: o.Product.Groups[0], product: o.Product, offer: o}
+		var values []string
+		for _, c := range columns {
+			values = append(values, c.value(r))
+		}
+		assert.Equal(t, tc.values, strings.Join(values, ";"), tc.id)
+	}
+}
@@ -1,95 +1,80 @@
 package main
 
 import (
-	"fmt"
+	"encoding/csv"
 	"log"
+	"os"
+	"path/filepath"
 	"strings"
+	"unicode/utf8"
 
+	"github.com/sevkin/go-cml/catalog"
 	"github.com/sevkin/go-cml/xml"
 	"github.com/vrischmann/envconfig"
 )
 
-type (
-	_offer struct {
-		*xml.Предложение
-	}
-
-	_product struct {
-		*xml.Товар
-		// offers map[string]
-	}
-
-	_group struct {
-		*xml.Группа
-		path     []string
-		products map[string]*_product
-	}
-
-	_data struct {
-		Import   *xml.КоммерческаяИнформация
-		groups   map[string]*_group   // *xml.Группа
-		products map[string]*_product // *xml.Товар
-		Offers   *xml.КоммерческаяИнформация
-		offers   map[string]*_offer // *xml.Предложение
-	}
-)
-
 var (
 	conf struct {
 		Import string `envconfig:"default=import.xml"`
 		Offers string `envconfig:"default=offers.xml"`
+		// Prices and Rests are semicolon separated prices*.xml and rests*.xml of CommerceML 3.x,
+		// those next to Offers are used if not set
+		Prices string `envconfig:"optional"`
+		Rests  string `envconfig:"optional"`
+		// Columns is comma separated column spec, see parseColumns
+		Columns   string `envconfig:"optional"`
+		Delimiter string `envconfig:"default=;"`
+		// BOM makes Excel recognize UTF-8
+		BOM bool `envconfig:"default=false"`
+		// Offerless emits products without offers with empty offer columns
+		Offerless bool `envconfig:"default=false"`
+		// OrphanGroup is the name of synthetic group for products
+		// not linked to classifier, they are skipped if empty
+		OrphanGroup string `envconfig:"optional"`
 	}
 )
 
-func (d *_data) addGroups(groups *[]xml.Группа, path []string) {
-	for idx := range *groups {
-		g := &_group{
-			Группа:   &(*groups)[idx],
-			path:     append(path, (*groups)[idx].Наименование),
-			products: make(map[string]*_product),
-		}
-
-		d.groups[g.Ид] = g
-		if g.Группы != nil {
-			d.addGroups(g.Группы, g.path)
-		}
+// offers returns offers of product to output or nil if product is skipped
+func offers(p *catalog.Product) []*catalog.Offer {
+	if p.Deleted() {
+		return nil
 	}
+	if len(p.Offers) == 0 && conf.Offerless {
+		return []*catalog.Offer{{Предложение: &xml.Предложение{Ид: p.Ид}, Product: p}}
+	}
+	return p.Offers
 }
 
-func (d *_data) addProducts(products *[]xml.Товар) {
-	for idx := range *products {
-		p := &_product{
-			Товар: &(*products)[idx],
-		}
-
-		d.products[p.Ид] = p
-		for _, gid := range p.Группы {
-			d.groups[gid].products[p.Ид] = p
+// summary logs skipped and invalid records,
+// every skipped product is counted once by its first reason
+func summary(data *catalog.Catalog) {
+	orphan := make(map[*catalog.Product]bool)
+	if len(conf.OrphanGroup) == 0 {
+		for _, p := range data.Orphans() {
+			orphan[p] = true
 		}
 	}
-}
 
-func (d *_data) addOffers(offers *[]xml.Предложение) {
-	for idx := range *offers {
-		o := &_offer{
-			Предложение: &(*offers)[idx],
+	var deleted, offerless, orphans int
+	for _, p := range data.Products() {
+		switch {
+		case p.Deleted():
+			deleted++
+		case orphan[p]:
+			orphans++
+		case len(p.Offers) == 0 && !conf.Offerless:
+			offerless++
+		}
+		for _, id := range p.Missing {
+			log.Printf("product %s refers to unknown group %s", p.Ид, id)
 		}
-
-		d.offers[o.Ид] = o
 	}
-}
-
-func (d *_data) init() {
-	d.Import = xml.ReadMust(conf.Import)
-	d.groups = make(map[string]*_group)
-	d.addGroups(&d.Import.Классификатор.Группы, append([]string{}))
-	d.products = make(map[string]*_product)
-	d.addProducts(&d.Import.Каталог.Товары)
-
-	d.Offers = xml.ReadMust(conf.Offers)
-	d.offers = make(map[string]*_offer)
-	d.addOffers(&d.Offers.ПакетПредложений.Предложения)
+	for _, o := range data.Unmatched() {
+		log.Printf("offer %s refers to unknown product", o.Ид)
+	}
 
+	log.Printf("skipped: deleted %d, without group %d, without offers %d; offers without product %d",
+		deleted, orphans, offerless, len(data.Unmatched()))
 }
 
 func main() {
@@ -98,33 +83,97 @@ func main() {
 		log.Fatal(err)
 	}
 
-	var data _data
-	data.init()
+	delimiter, size := utf8.DecodeRuneInString(conf.Delimiter)
+	if size == 0 || size != len(conf.Delimiter) {
+		log.Fatalf("delimiter must be single character: %q", conf.Delimiter)
+	}
+	if len(conf.Columns) == 0 {
+		conf.Columns = defaultColumns
+	}
 
-	log.Printf("groups: %d goods: %d offers: %d",
-		len(data.groups), len(data.products), len(data.offers))
+	fnames := []string{conf.Import, conf.Offers}
+	for _, list := range []struct{ files, pattern string }{
+		{conf.Prices, "prices*.xml"},
+		{conf.Rests, "rests*.xml"},
+	} {
+		if len(list.files) == 0 {
+			found, _ := filepath.Glob(filepath.Join(filepath.Dir(conf.Offers), list.pattern))
+			for _, fname := range found {
+				log.Printf("found %s", fname)
+			}
+			fnames = append(fnames, found...)
+			continue
+		}
+		for _, fname := range strings.Split(list.files, ";") {
+			if fname = strings.TrimSpace(fname); len(fname) > 0 {
+				fnames = append(fnames, fname)
+			}
+		}
+	}
 
-	// CSV header
-	// fmt.Printf("\n")
+	data, err := catalog.LoadFiles(fnames...)
+	if err != nil {
+		log.Fatal(err)
+	}
 
-	// sku, path, name, ... offers
-	for _, g := range data.groups {
-		if len(g.products) == 0 {
-			continue
+	columns, err := parseColumns(conf.Columns, data)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	groups := data.AllGroups()
+	log.Printf("groups: %d goods: %d offers: %d",
+		len(groups), len(data.Products()), len(data.Offers()))
+
+	if conf.BOM {
+		if _, err := os.Stdout.WriteString("\xEF\xBB\xBF"); err != nil {
+			log.Fatal(err)
 		}
+	}
+
+	w := csv.NewWriter(os.Stdout)
+	w.Comma = delimiter
 
-		for _, p := range g.products {
-			o, found := data.offers[p.Ид]
-			if !found {
-				continue
+	record := make([]string, len(columns))
+	for idx, c := range columns {
+		record[idx] = c.header
+	}
+	if err := w.Write(record); err != nil {
+		log.Fatal(err)
+	}
+
+	write := func(g *catalog.Group, p *catalog.Product) {
+		// product itself and its variants
+		for _, o := range offers(p) {
+			r := &row{group: g, product: p, offer: o}
+			for idx, c := range columns {
+				record[idx] = c.value(r)
 			}
+			if err := w.Write(record); err != nil {
+				log.Fatal(err)
+			}
+		}
+	}
 
-			fmt.Printf("%s; %s; %s; %s\n",
-				p.Артикул,
-				strings.Join(g.path, ">"),
-				p.Наименование,
-				o.Количество,
-			)
+	for _, g := range groups {
+		for _, p := range g.Products {
+			write(g, p)
 		}
 	}
+	if len(conf.OrphanGroup) > 0 {
+		orphans := &catalog.Group{
+			Группа: &xml.Группа{Наименование: conf.OrphanGroup},
+			Path:   []string{conf.OrphanGroup},
+		}
+		for _, p := range data.Orphans() {
+			write(orphans, p)
+		}
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		log.Fatal(err)
+	}
+
+	summary(data)
 }
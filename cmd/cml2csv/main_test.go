@@ -0,0 +1,108 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"log"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sevkin/go-cml/catalog"
+	"github.com/sevkin/go-cml/xml"
+)
+
+func load(t *testing.T) *catalog.Catalog {
+	data, err := catalog.LoadFiles("../../xml/testdata/import.xml", "../../xml/testdata/offers.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// one more deleted product, orphan and offerless ones
+	data.Add(&xml.КоммерческаяИнформация{Каталог: &xml.Каталог{Товары: []xml.Товар{
+		{Ид: "deleted", Наименование: "Удален", ПометкаУдаления: true},
+		{Ид: "orphan", Наименование: "Без группы"},
+		{Ид: "offerless", Наименование: "Без предложений", Группы: []string{"9e1f0a13-2b3c-11e9-80d4-0cc47a7c2f11"}},
+	}}})
+	return data
+}
+
+func TestCSVQuoting(t *testing.T) {
+	for _, tc := range []struct {
+		value, line string
+	}{
+		{"Брюки льняные", "Брюки льняные"},
+		{"Брюки; льняные", `"Брюки; льняные"`},
+		{`Кеды "Детские"`, `"Кеды ""Детские"""`},
+		{"Платье\nлетнее", "\"Платье\nлетнее\""},
+		{" 44", `" 44"`},
+	} {
+		var buf bytes.Buffer
+		w := csv.NewWriter(&buf)
+		w.Comma = ';'
+		assert.Nil(t, w.Write([]string{tc.value, "1"}))
+		w.Flush()
+		assert.Nil(t, w.Error())
+		assert.Equal(t, tc.line+";1\n", buf.String())
+
+		r := csv.NewReader(&buf)
+		r.Comma = ';'
+		back, err := r.Read()
+		assert.Nil(t, err)
+		assert.Equal(t, []string{tc.value, "1"}, back, tc.value)
+	}
+}
+
+func TestOffers(t *testing.T) {
+	defer func(offerless bool) { conf.Offerless = offerless }(conf.Offerless)
+	data := load(t)
+
+	for _, tc := range []struct {
+		id        string
+		offerless bool
+		count     int
+	}{
+		{"c5e4a1b0-3d2e-11e9-80d4-0cc47a7c2f11", false, 2},
+		{"deleted", false, 0},
+		{"deleted", true, 0},
+		{"offerless", false, 0},
+		{"offerless", true, 1},
+		{"orphan", true, 1},
+	} {
+		conf.Offerless = tc.offerless
+		p := data.Product(tc.id)
+		list := offers(p)
+		assert.Equal(t, tc.count, len(list), tc.id)
+		if tc.offerless && tc.count > 0 {
+			assert.Equal(t, p.Ид, list[0].Ид)
+			assert.Equal(t, p, list[0].Product)
+		}
+	}
+}
+
+func TestSummary(t *testing.T) {
+	defer func(offerless bool, orphan string) {
+		conf.Offerless, conf.OrphanGroup = offerless, orphan
+	}(conf.Offerless, conf.OrphanGroup)
+	defer log.SetOutput(os.Stderr)
+	data := load(t)
+
+	for _, tc := range []struct {
+		offerless bool
+		orphan    string
+		skipped   string
+	}{
+		{false, "", "deleted 2, without group 1, without offers 1"},
+		{true, "", "deleted 2, without group 1, without offers 0"},
+		// orphan group keeps orphans, offerless orphan is counted once
+		{false, "Прочее", "deleted 2, without group 0, without offers 2"},
+		{true, "Прочее", "deleted 2, without group 0, without offers 0"},
+	} {
+		conf.Offerless, conf.OrphanGroup = tc.offerless, tc.orphan
+		var buf bytes.Buffer
+		log.SetOutput(&buf)
+		summary(data)
+		assert.True(t, strings.Contains(buf.String(), "skipped: "+tc.skipped), buf.String())
+	}
+}
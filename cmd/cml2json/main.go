@@ -0,0 +1,49 @@
+package main
+
+import (
+	"log"
+	"os"
+
+	"github.com/sevkin/go-cml/json"
+	"github.com/sevkin/go-cml/xml"
+	"github.com/vrischmann/envconfig"
+)
+
+var (
+	conf struct {
+		// Stream writes NDJSON, one product with its offers per line
+		Stream bool `envconfig:"default=false"`
+	}
+)
+
+func main() {
+	err := envconfig.InitWithPrefix(&conf, "CML2JSON")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if len(os.Args) < 2 {
+		log.Fatalf("usage: %s import.xml [offers.xml...]", os.Args[0])
+	}
+
+	// import and offers are joined into single document
+	var x *xml.КоммерческаяИнформация
+	for _, fname := range os.Args[1:] {
+		doc, err := xml.ReadFile(fname)
+		if err != nil {
+			log.Fatalf("%s: %v", fname, err)
+		}
+		if x, err = xml.Merge(x, doc); err != nil {
+			log.Fatalf("%s: %v", fname, err)
+		}
+	}
+
+	if conf.Stream {
+		err = json.WriteStream(x, os.Stdout)
+	} else {
+		err = json.Write(x, os.Stdout)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"log"
+	"os"
+	"strings"
+
+	"github.com/sevkin/go-cml/catalog"
+	"github.com/sevkin/go-cml/merchant"
+	"github.com/vrischmann/envconfig"
+)
+
+var (
+	conf struct {
+		Import      string `envconfig:"default=import.xml"`
+		Offers      string `envconfig:"default=offers.xml"`
+		Title       string `envconfig:"optional"`
+		Link        string `envconfig:"optional"`
+		Description string `envconfig:"optional"`
+		// PriceType is Ид or Наименование, the first price type if empty
+		PriceType string `envconfig:"optional"`
+		// Warehouses is semicolon separated Ид or Наименование of warehouses
+		// to sum stock on, Количество or all stock of offer is used if empty
+		Warehouses string `envconfig:"optional"`
+		// ItemLink is text/template executed on catalog.Offer,
+		// e.g. https://example.com/catalog/{{.Product.Ид}}
+		ItemLink string `envconfig:"optional"`
+		// ImageLink is text/template executed on merchant.Image,
+		// e.g. https://example.com/upload/{{.Path}}
+		ImageLink string `envconfig:"optional"`
+	}
+)
+
+func main() {
+	err := envconfig.InitWithPrefix(&conf, "CML2MERCHANT")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	data, err := catalog.LoadFiles(conf.Import, conf.Offers)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	opt := merchant.Options{
+		Title:       conf.Title,
+		Link:        conf.Link,
+		Description: conf.Description,
+		PriceType:   conf.PriceType,
+		ItemLink:    conf.ItemLink,
+		ImageLink:   conf.ImageLink,
+	}
+	if len(conf.Warehouses) > 0 {
+		opt.Warehouses = strings.Split(conf.Warehouses, ";")
+	}
+
+	feed, err := merchant.Convert(data, opt)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err := merchant.Write(feed, os.Stdout); err != nil {
+		log.Fatal(err)
+	}
+
+	for _, s := range feed.Skipped {
+		log.Printf("skipped %s", s)
+	}
+	log.Printf("items: %d skipped: %d", len(feed.Channel.Items), len(feed.Skipped))
+}
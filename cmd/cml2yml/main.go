@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/sevkin/go-cml/catalog"
+	"github.com/sevkin/go-cml/currency"
+	"github.com/sevkin/go-cml/yml"
+	"github.com/vrischmann/envconfig"
+)
+
+var (
+	conf struct {
+		Import  string `envconfig:"default=import.xml"`
+		Offers  string `envconfig:"default=offers.xml"`
+		Shop    string `envconfig:"optional"`
+		Company string `envconfig:"optional"`
+		URL     string `envconfig:"optional"`
+		// PriceType is Ид or Наименование, the first price type if empty
+		PriceType string `envconfig:"optional"`
+		// Warehouses is semicolon separated Ид or Наименование of warehouses
+		// to sum stock on, Количество or all stock of offer is used if empty
+		Warehouses string `envconfig:"optional"`
+		// ImageBase is prepended to picture path, e.g. https://example.com/upload/
+		ImageBase string `envconfig:"optional"`
+		// OfferURL is text/template executed on catalog.Offer,
+		// e.g. https://example.com/catalog/{{.Product.Артикул}}
+		OfferURL string `envconfig:"optional"`
+		// Currency is ISO code of base currency, currency of price type if empty
+		Currency string `envconfig:"optional"`
+		// Rates is semicolon separated rates of other currencies to base one, e.g. USD:63.5;EUR:71
+		Rates string `envconfig:"optional"`
+	}
+)
+
+// parseRates parses CODE:rate;CODE:rate
+func parseRates(s string) (map[string]float64, error) {
+	rates := make(map[string]float64)
+	for _, item := range strings.Split(s, ";") {
+		if len(strings.TrimSpace(item)) == 0 {
+			continue
+		}
+		kv := strings.SplitN(item, ":", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("bad rate %q, CODE:rate expected", item)
+		}
+		rate, err := strconv.ParseFloat(strings.Replace(strings.TrimSpace(kv[1]), ",", ".", 1), 64)
+		if err != nil || rate <= 0 {
+			return nil, fmt.Errorf("bad rate %q", item)
+		}
+		rates[currency.Code(kv[0])] = rate
+	}
+	return rates, nil
+}
+
+func main() {
+	err := envconfig.InitWithPrefix(&conf, "CML2YML")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	data, err := catalog.LoadFiles(conf.Import, conf.Offers)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	opt := yml.Options{
+		Shop:      conf.Shop,
+		Company:   conf.Company,
+		URL:       conf.URL,
+		PriceType: conf.PriceType,
+		ImageBase: conf.ImageBase,
+		OfferURL:  conf.OfferURL,
+		Currency:  conf.Currency,
+	}
+	if len(conf.Warehouses) > 0 {
+		opt.Warehouses = strings.Split(conf.Warehouses, ";")
+	}
+	if opt.Rates, err = parseRates(conf.Rates); err != nil {
+		log.Fatal(err)
+	}
+
+	feed, err := yml.Convert(data, opt)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err := yml.Write(feed, os.Stdout); err != nil {
+		log.Fatal(err)
+	}
+
+	for _, s := range feed.Skipped {
+		log.Printf("skipped %s", s)
+	}
+	log.Printf("categories: %d offers: %d skipped: %d",
+		len(feed.Shop.Categories), len(feed.Shop.Offers), len(feed.Skipped))
+}
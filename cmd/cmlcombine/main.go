@@ -0,0 +1,46 @@
+package main
+
+import (
+	"log"
+	"os"
+
+	"github.com/sevkin/go-cml/xml"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		log.Fatalf("usage: %s dir | file.xml...", os.Args[0])
+	}
+
+	var (
+		x   *xml.КоммерческаяИнформация
+		err error
+	)
+	if info, serr := os.Stat(os.Args[1]); serr == nil && info.IsDir() && len(os.Args) == 2 {
+		x, err = xml.CombineDir(os.Args[1])
+	} else {
+		docs := make([]*xml.КоммерческаяИнформация, 0, len(os.Args)-1)
+		for _, fname := range os.Args[1:] {
+			doc, err := xml.ReadFile(fname)
+			if err != nil {
+				log.Fatalf("%s: %v", fname, err)
+			}
+			docs = append(docs, doc)
+		}
+		x, err = xml.Combine(docs...)
+	}
+
+	if conflicts, ok := err.(xml.Conflicts); ok {
+		for _, c := range conflicts {
+			log.Print(c)
+		}
+		log.Fatalf("%d conflicts", len(conflicts))
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err := xml.Write(x, os.Stdout); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"log"
+	"os"
+
+	"github.com/sevkin/go-cml/xml"
+	"github.com/vrischmann/envconfig"
+)
+
+var (
+	conf struct {
+		// Dir to write parts to
+		Dir string `envconfig:"default=."`
+		// Products per part, 0 means no limit
+		Products int `envconfig:"default=0"`
+		// Bytes per part, e.g. file_limit of server, 0 means no limit
+		Bytes int `envconfig:"default=0"`
+	}
+)
+
+func main() {
+	err := envconfig.InitWithPrefix(&conf, "CMLSPLIT")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if len(os.Args) < 2 {
+		log.Fatalf("usage: %s import.xml [offers.xml...]", os.Args[0])
+	}
+
+	limits := xml.SplitLimits{Products: conf.Products, Bytes: conf.Bytes}
+	for _, fname := range os.Args[1:] {
+		x, err := xml.ReadFile(fname)
+		if err != nil {
+			log.Fatalf("%s: %v", fname, err)
+		}
+		names, err := xml.WriteSplit(x, limits, conf.Dir)
+		if err != nil {
+			log.Fatalf("%s: %v", fname, err)
+		}
+		log.Printf("%s: %v", fname, names)
+	}
+}
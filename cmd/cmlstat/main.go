@@ -0,0 +1,107 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/sevkin/go-cml/catalog"
+	"github.com/sevkin/go-cml/xml"
+	"github.com/vrischmann/envconfig"
+)
+
+var (
+	conf struct {
+		// JSON prints report as JSON instead of text
+		JSON bool `envconfig:"default=false"`
+		// Limit of Ид listed per text report section, 0 lists none
+		Limit int `envconfig:"default=10"`
+	}
+)
+
+// list prints count and up to conf.Limit ids
+func list(w io.Writer, title string, ids []string) {
+	fmt.Fprintf(w, "%s: %d\n", title, len(ids))
+	if conf.Limit == 0 {
+		return
+	}
+	for idx, id := range ids {
+		if idx == conf.Limit {
+			fmt.Fprintf(w, "  ...\n")
+			break
+		}
+		fmt.Fprintf(w, "  %s\n", id)
+	}
+}
+
+func text(w io.Writer, r *report) {
+	for _, d := range r.Documents {
+		fmt.Fprintf(w, "%s: version %s date %s", d.File, d.Version, d.Date)
+		if d.OnlyChanges {
+			fmt.Fprintf(w, " (only changes)")
+		}
+		fmt.Fprintf(w, "\n")
+	}
+
+	fmt.Fprintf(w, "\ngroups: %d\n", r.Groups)
+	for depth, count := range r.GroupsByDepth {
+		fmt.Fprintf(w, "  depth %d: %d\n", depth+1, count)
+	}
+	fmt.Fprintf(w, "\nproducts by group:\n")
+	for _, g := range r.ProductsByGroup {
+		fmt.Fprintf(w, "  %6d %s\n", g.Products, g.Path)
+	}
+
+	fmt.Fprintf(w, "\nproducts: %d deleted: %d\n", r.Products, r.Deleted)
+	list(w, "without article", r.WithoutArticle)
+	list(w, "without images", r.WithoutImages)
+	list(w, "without offers", r.WithoutOffers)
+	fmt.Fprintf(w, "duplicate articles: %d\n", len(r.DuplicateArticles))
+	for _, article := range r.duplicates() {
+		fmt.Fprintf(w, "  %s: %s\n", article, strings.Join(r.DuplicateArticles[article], ", "))
+	}
+
+	fmt.Fprintf(w, "\noffers: %d\n", r.Offers)
+	list(w, "zero stock", r.ZeroStock)
+	fmt.Fprintf(w, "prices:\n")
+	for _, p := range r.Prices {
+		fmt.Fprintf(w, "  %s (%s): %d, %.2f - %.2f\n", p.Name, p.Currency, p.Count, p.Min, p.Max)
+	}
+}
+
+func main() {
+	err := envconfig.InitWithPrefix(&conf, "CMLSTAT")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if len(os.Args) < 2 {
+		log.Fatalf("usage: %s import.xml [offers.xml...]", os.Args[0])
+	}
+
+	data := catalog.New()
+	var docs []document
+	for _, fname := range os.Args[1:] {
+		x, err := xml.ReadFile(fname)
+		if err != nil {
+			log.Fatalf("%s: %v", fname, err)
+		}
+		data.Add(x)
+		docs = append(docs, describe(fname, x))
+	}
+
+	r := build(data, docs)
+
+	if conf.JSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(r); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	text(os.Stdout, r)
+}
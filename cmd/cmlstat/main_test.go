@@ -0,0 +1,26 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestList(t *testing.T) {
+	defer func(limit int) { conf.Limit = limit }(conf.Limit)
+
+	for _, tc := range []struct {
+		limit int
+		text  string
+	}{
+		{0, "ids: 2\n"},
+		{1, "ids: 2\n  a\n  ...\n"},
+		{2, "ids: 2\n  a\n  b\n"},
+	} {
+		conf.Limit = tc.limit
+		var buf bytes.Buffer
+		list(&buf, "ids", []string{"a", "b"})
+		assert.Equal(t, tc.text, buf.String())
+	}
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/sevkin/go-cml/catalog"
+	"github.com/sevkin/go-cml/xml"
+)
+
+type (
+	// report of catalog, JSON field names are the part of cmlstat output
+	report struct {
+		Documents         []document          `json:"documents"`
+		Groups            int                 `json:"groups"`
+		GroupsByDepth     []int               `json:"groups_by_depth"` // index 0 is top level
+		ProductsByGroup   []groupCount        `json:"products_by_group"`
+		Products          int                 `json:"products"`
+		Deleted           int                 `json:"deleted"`
+		WithoutArticle    []string            `json:"without_article"`
+		WithoutImages     []string            `json:"without_images"`
+		WithoutOffers     []string            `json:"without_offers"`
+		Offers            int                 `json:"offers"`
+		ZeroStock         []string            `json:"zero_stock"`
+		Prices            []priceRange        `json:"prices"`
+		DuplicateArticles map[string][]string `json:"duplicate_articles"`
+	}
+
+	document struct {
+		File         string `json:"file"`
+		Version      string `json:"version"`
+		Date         string `json:"date"`
+		OnlyChanges  bool   `json:"only_changes"`
+		Products     int    `json:"products,omitempty"`
+		Offers       int    `json:"offers,omitempty"`
+		Classifier   string `json:"classifier,omitempty"`
+		OffersPackID string `json:"offers_package,omitempty"`
+	}
+
+	groupCount struct {
+		Path     string `json:"path"`
+		Products int    `json:"products"`
+	}
+
+	priceRange struct {
+		ID       string  `json:"id"`
+		Name     string  `json:"name"`
+		Currency string  `json:"currency"`
+		Count    int     `json:"count"`
+		Min      float64 `json:"min"`
+		Max      float64 `json:"max"`
+	}
+)
+
+func number(s string) (float64, bool) {
+	v, err := strconv.ParseFloat(strings.Replace(strings.TrimSpace(s), ",", ".", 1), 64)
+	return v, err == nil
+}
+
+func describe(fname string, x *xml.КоммерческаяИнформация) document {
+	d := document{File: fname, Version: x.ВерсияСхемы, Date: x.ДатаФормирования}
+	if x.Классификатор != nil {
+		d.Classifier = x.Классификатор.Ид
+	}
+	if x.Каталог != nil {
+		d.OnlyChanges = x.Каталог.СодержитТолькоИзменения
+		d.Products = len(x.Каталог.Товары)
+	}
+	if x.ПакетПредложений != nil {
+		d.OnlyChanges = d.OnlyChanges || x.ПакетПредложений.СодержитТолькоИзменения
+		d.Offers = len(x.ПакетПредложений.Предложения)
+		d.OffersPackID = x.ПакетПредложений.Ид
+	}
+	if change := x.ИзменениеПакетаПредложений; change != nil {
+		d.OnlyChanges = d.OnlyChanges || change.СодержитТолькоИзменения
+		d.Offers += len(change.Предложения)
+		d.OffersPackID = change.Ид
+	}
+	return d
+}
+
+func build(data *catalog.Catalog, docs []document) *report {
+	r := &report{
+		Documents:         docs,
+		DuplicateArticles: make(map[string][]string),
+	}
+
+	for _, g := range data.AllGroups() {
+		r.Groups++
+		depth := len(g.Path) - 1
+		for len(r.GroupsByDepth) <= depth {
+			r.GroupsByDepth = append(r.GroupsByDepth, 0)
+		}
+		r.GroupsByDepth[depth]++
+		r.ProductsByGroup = append(r.ProductsByGroup, groupCount{
+			Path:     strings.Join(g.Path, "/"),
+			Products: len(g.Products),
+		})
+	}
+
+	for _, p := range data.Products() {
+		r.Products++
+		if p.Deleted() {
+			r.Deleted++
+			continue
+		}
+		if len(p.Артикул) == 0 {
+			r.WithoutArticle = append(r.WithoutArticle, p.Ид)
+		} else if same := data.ProductsByArticle(p.Артикул); len(same) > 1 {
+			r.DuplicateArticles[p.Артикул] = append(r.DuplicateArticles[p.Артикул], p.Ид)
+		}
+		if len(p.Картинка) == 0 {
+			r.WithoutImages = append(r.WithoutImages, p.Ид)
+		}
+		if len(p.Offers) == 0 {
+			r.WithoutOffers = append(r.WithoutOffers, p.Ид)
+		}
+	}
+	// deleted products do not make article duplicated
+	for article, ids := range r.DuplicateArticles {
+		if len(ids) < 2 {
+			delete(r.DuplicateArticles, article)
+		}
+	}
+
+	ranges := make(map[string]*priceRange)
+	for _, t := range data.PriceTypes() {
+		ranges[t.Ид] = &priceRange{ID: t.Ид, Name: t.Наименование, Currency: t.Валюта}
+	}
+	for _, o := range data.Offers() {
+		r.Offers++
+		if o.Quantity() <= 0 {
+			r.ZeroStock = append(r.ZeroStock, o.Ид)
+		}
+		for _, price := range o.Цены {
+			v, ok := number(price.ЦенаЗаЕдиницу)
+			pr := ranges[price.ИдТипаЦены]
+			if !ok || pr == nil {
+				continue
+			}
+			if pr.Count == 0 || v < pr.Min {
+				pr.Min = v
+			}
+			if pr.Count == 0 || v > pr.Max {
+				pr.Max = v
+			}
+			pr.Count++
+		}
+	}
+	for _, t := range data.PriceTypes() {
+		r.Prices = append(r.Prices, *ranges[t.Ид])
+	}
+
+	return r
+}
+
+// duplicates returns sorted duplicated articles
+func (r *report) duplicates() []string {
+	articles := make([]string, 0, len(r.DuplicateArticles))
+	for article := range r.DuplicateArticles {
+		articles = append(articles, article)
+	}
+	sort.Strings(articles)
+	return articles
+}
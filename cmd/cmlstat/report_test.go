@@ -0,0 +1,62 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sevkin/go-cml/catalog"
+	"github.com/sevkin/go-cml/xml"
+)
+
+func load(fnames ...string) (*catalog.Catalog, []document) {
+	data := catalog.New()
+	var docs []document
+	for _, fname := range fnames {
+		x := xml.ReadMust("../../xml/testdata/" + fname)
+		data.Add(x)
+		docs = append(docs, describe(fname, x))
+	}
+	return data, docs
+}
+
+func TestReport(t *testing.T) {
+	r := build(load("import.xml", "offers.xml"))
+
+	assert.Equal(t, 2, len(r.Documents))
+	assert.Equal(t, 4, r.Documents[0].Products)
+	assert.Equal(t, 4, r.Documents[1].Offers)
+	assert.Equal(t, 4, r.Groups)
+	assert.Equal(t, []int{2, 2}, r.GroupsByDepth)
+	assert.Equal(t, 4, r.Products)
+	assert.Equal(t, 1, r.Deleted)
+	assert.Equal(t, 4, r.Offers)
+	assert.Equal(t, []string{"c5e4a1b0-3d2e-11e9-80d4-0cc47a7c2f11#8a9b0c1e-6e7f-11e9-80d4-0cc47a7c2f11"}, r.ZeroStock)
+	assert.Empty(t, r.DuplicateArticles)
+
+	assert.Equal(t, 2, len(r.Prices))
+	assert.Equal(t, "Розничная", r.Prices[0].Name)
+	assert.Equal(t, 4, r.Prices[0].Count)
+	assert.Equal(t, 3450.5, r.Prices[0].Max)
+}
+
+func TestReportRests(t *testing.T) {
+	data, docs := load("import.xml", "offers.xml", "prices.xml", "rests.xml")
+	r := build(data, docs)
+	assert.Equal(t, 2, docs[3].Offers)
+	assert.True(t, docs[3].OnlyChanges)
+	assert.Equal(t, []string{
+		"c5e4a1b0-3d2e-11e9-80d4-0cc47a7c2f11#8a9b0c1e-6e7f-11e9-80d4-0cc47a7c2f11",
+		"c5e4a1b2-3d2e-11e9-80d4-0cc47a7c2f11",
+	}, r.ZeroStock)
+	assert.Equal(t, 3300.0, r.Prices[0].Max)
+
+	// stock by warehouses only is not zero
+	data.Add(&xml.КоммерческаяИнформация{ПакетПредложений: &xml.ПакетПредложений{
+		СодержитТолькоИзменения: true,
+		Предложения: []xml.Предложение{{Ид: "c5e4a1b2-3d2e-11e9-80d4-0cc47a7c2f11",
+			Склад: []xml.Остаток{{ИдСклада: "e2f1b3c4-5d6e-11e9-80d4-0cc47a7c2f11", КоличествоНаСкладе: "2"}}}},
+	}})
+	r = build(data, docs)
+	assert.Equal(t, []string{"c5e4a1b0-3d2e-11e9-80d4-0cc47a7c2f11#8a9b0c1e-6e7f-11e9-80d4-0cc47a7c2f11"}, r.ZeroStock)
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/sevkin/go-cml/xml/xsd"
+	"github.com/vrischmann/envconfig"
+)
+
+var (
+	conf struct {
+		// Version forces ВерсияСхемы instead of the one declared in document
+		Version string `envconfig:"optional"`
+	}
+)
+
+func validate(fname string) error {
+	if len(conf.Version) == 0 {
+		return xsd.ValidateFile(fname)
+	}
+
+	s, err := xsd.ForVersion(conf.Version)
+	if err != nil {
+		return err
+	}
+	f, err := os.Open(fname)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return s.Validate(f)
+}
+
+func main() {
+	err := envconfig.InitWithPrefix(&conf, "CMLXSD")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if len(os.Args) < 2 {
+		log.Fatalf("usage: %s file.xml...", os.Args[0])
+	}
+
+	failed := false
+	for _, fname := range os.Args[1:] {
+		err := validate(fname)
+		if err == nil {
+			continue
+		}
+
+		failed = true
+		if errs, ok := err.(xsd.Errors); ok {
+			for _, e := range errs {
+				fmt.Printf("%s:%s\n", fname, e)
+			}
+		} else {
+			fmt.Printf("%s: %s\n", fname, err)
+		}
+	}
+
+	if failed {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"encoding/csv"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/sevkin/go-cml/builder"
+	"github.com/sevkin/go-cml/xml"
+	"github.com/vrischmann/envconfig"
+)
+
+var (
+	conf struct {
+		Input  string `envconfig:"default=catalog.csv"`
+		Import string `envconfig:"default=import.xml"`
+		Offers string `envconfig:"default=offers.xml"`
+		// Mapping is comma separated field=Header pairs, see parseMapping
+		Mapping   string `envconfig:"optional"`
+		Delimiter string `envconfig:"default=;"`
+		// Owner is Наименование of Владелец
+		Owner    string
+		Catalog  string `envconfig:"default=Основной каталог товаров"`
+		Currency string `envconfig:"default=RUB"`
+	}
+)
+
+func writeFile(fname string, x *xml.КоммерческаяИнформация) error {
+	f, err := os.Create(fname)
+	if err != nil {
+		return err
+	}
+	if err := xml.Write(x, f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func main() {
+	err := envconfig.InitWithPrefix(&conf, "CSV2CML")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	delimiter, size := utf8.DecodeRuneInString(conf.Delimiter)
+	if size == 0 || size != len(conf.Delimiter) {
+		log.Fatalf("delimiter must be single character: %q", conf.Delimiter)
+	}
+	if len(conf.Mapping) == 0 {
+		conf.Mapping = defaultMapping
+	}
+
+	f, err := os.Open(conf.Input)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer f.Close()
+
+	r := csv.NewReader(f)
+	r.Comma = delimiter
+	r.FieldsPerRecord = -1
+
+	header, err := r.Read()
+	if err != nil {
+		log.Fatal(err)
+	}
+	if len(header) > 0 {
+		header[0] = strings.TrimPrefix(header[0], "\xEF\xBB\xBF")
+	}
+	m, err := parseMapping(conf.Mapping, header)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	owner := xml.Владелец{Наименование: conf.Owner}
+	imp := builder.NewImport().
+		Owner(owner).
+		Classifier("", conf.Catalog).
+		Catalog("", conf.Catalog)
+	offers := builder.NewOffers().
+		Owner(owner)
+	for idx, t := range m.prices {
+		m.prices[idx].id = builder.ID("price", t.name)
+		offers.PriceType(m.prices[idx].id, t.name, conf.Currency)
+	}
+	for idx, s := range m.stocks {
+		m.stocks[idx].id = builder.ID("warehouse", s.name)
+		offers.Warehouse(m.stocks[idx].id, s.name)
+	}
+
+	groups := make(map[string]bool)
+	products := make(map[string]bool)
+	var items []xml.Предложение
+	for line := 2; ; line++ {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		name := value(record, m.name)
+		if len(name) == 0 {
+			log.Printf("%s:%d: skipped without name", conf.Input, line)
+			continue
+		}
+
+		// group tree from path A>B>C
+		parent := ""
+		for _, g := range splitPath(value(record, m.path)) {
+			path := g
+			if len(parent) > 0 {
+				path = parent + ">" + g
+			}
+			if !groups[path] {
+				groups[path] = true
+				imp.Group("", g, parent)
+			}
+			parent = path
+		}
+
+		key := value(record, m.sku)
+		if len(key) == 0 {
+			key = parent + ">" + name
+		}
+		if products[key] {
+			log.Printf("%s:%d: skipped duplicate %s", conf.Input, line, key)
+			continue
+		}
+		products[key] = true
+
+		p := xml.Товар{
+			Ид:             builder.ID("product", key),
+			Артикул:        value(record, m.sku),
+			Наименование:   name,
+			БазоваяЕдиница: xml.БазоваяЕдиница{БазоваяЕдиница: value(record, m.unit)},
+		}
+		var in []string
+		if len(parent) > 0 {
+			in = append(in, parent)
+		}
+		imp.Product(p, in...)
+
+		// offer refers to product by the same Ид
+		o := xml.Предложение{
+			Ид:             p.Ид,
+			Артикул:        p.Артикул,
+			Наименование:   p.Наименование,
+			БазоваяЕдиница: p.БазоваяЕдиница,
+			Количество:     value(record, m.quantity),
+		}
+		for _, t := range m.prices {
+			if price := value(record, t.column); len(price) > 0 {
+				o.Цены = append(o.Цены, xml.Цена{ИдТипаЦены: t.id, ЦенаЗаЕдиницу: price})
+			}
+		}
+		for _, s := range m.stocks {
+			if stock := value(record, s.column); len(stock) > 0 {
+				o.Склад = append(o.Склад, xml.Остаток{ИдСклада: s.id, КоличествоНаСкладе: stock})
+			}
+		}
+		items = append(items, o)
+	}
+
+	x, err := imp.Build()
+	if err != nil {
+		log.Fatal(err)
+	}
+	if x.Каталог == nil {
+		log.Fatalf("%s: no products", conf.Input)
+	}
+
+	offers.Package("", "Пакет предложений ("+conf.Catalog+")", x.Каталог.Ид, x.Классификатор.Ид)
+	for _, o := range items {
+		offers.Offer(o)
+	}
+	y, err := offers.Build()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err := writeFile(conf.Import, x); err != nil {
+		log.Fatal(err)
+	}
+	if err := writeFile(conf.Offers, y); err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("groups: %d goods: %d", len(groups), len(x.Каталог.Товары))
+}
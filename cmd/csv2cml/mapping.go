@@ -0,0 +1,110 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"strings"
+)
+
+// defaultMapping is used when CSV2CML_MAPPING is not set,
+// headers are those of sku, path, name and quantity columns of cml2csv.
+// Price and stock columns are named after price types and warehouses,
+// so they are mapped explicitly with price:<ТипЦены> and stock:<Склад>
+const defaultMapping = "sku=Артикул,path=Группа,name=Наименование,quantity=Количество"
+
+type (
+	// mapping of catalog fields to indexes of csv columns, -1 if absent
+	mapping struct {
+		sku, name, path, unit, quantity int
+		prices                          []target
+		stocks                          []target
+	}
+
+	// target is price type or warehouse filled from csv column
+	target struct {
+		name   string
+		column int
+		id     string
+	}
+)
+
+// parseMapping parses comma separated field=Header pairs:
+//
+//	sku, name, path, unit, quantity, price:<ТипЦены>, stock:<Склад>
+//
+// Header is the name of column in the first line of csv. Pairs with commas
+// are quoted as csv fields: "price:Розничная=Розничная, RUB"
+func parseMapping(spec string, header []string) (*mapping, error) {
+	pairs, err := csv.NewReader(strings.NewReader(spec)).Read()
+	if err != nil {
+		return nil, fmt.Errorf("mapping: %v", err)
+	}
+
+	columns := make(map[string]int)
+	for idx, h := range header {
+		columns[strings.TrimSpace(h)] = idx
+	}
+
+	m := &mapping{sku: -1, name: -1, path: -1, unit: -1, quantity: -1}
+	for _, pair := range pairs {
+		pair = strings.TrimSpace(pair)
+		if len(pair) == 0 {
+			continue
+		}
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("mapping %s: field=Header expected", pair)
+		}
+		field, h := strings.TrimSpace(kv[0]), strings.TrimSpace(kv[1])
+		column, found := columns[h]
+		if !found {
+			// optional columns of default mapping may be absent
+			if spec == defaultMapping && field != "name" {
+				continue
+			}
+			return nil, fmt.Errorf("mapping %s: column %s not found", pair, h)
+		}
+
+		switch {
+		case field == "sku":
+			m.sku = column
+		case field == "name":
+			m.name = column
+		case field == "path":
+			m.path = column
+		case field == "unit":
+			m.unit = column
+		case field == "quantity":
+			m.quantity = column
+		case strings.HasPrefix(field, "price:"):
+			m.prices = append(m.prices, target{name: strings.TrimPrefix(field, "price:"), column: column})
+		case strings.HasPrefix(field, "stock:"):
+			m.stocks = append(m.stocks, target{name: strings.TrimPrefix(field, "stock:"), column: column})
+		default:
+			return nil, fmt.Errorf("mapping %s: unknown field %s", pair, field)
+		}
+	}
+	if m.name < 0 {
+		return nil, fmt.Errorf("mapping: name is required")
+	}
+	return m, nil
+}
+
+// value returns trimmed value of record column or empty string
+func value(record []string, column int) string {
+	if column < 0 || column >= len(record) {
+		return ""
+	}
+	return strings.TrimSpace(record[column])
+}
+
+// splitPath splits group path "A>B>C" to names
+func splitPath(path string) []string {
+	var names []string
+	for _, name := range strings.Split(path, ">") {
+		if name = strings.TrimSpace(name); len(name) > 0 {
+			names = append(names, name)
+		}
+	}
+	return names
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// header of cml2csv default columns over xml/testdata
+const cml2csvHeader = "Артикул;Группа;Наименование;Количество;Розничная, RUB;Оптовая, RUB;Основной склад;Магазин на Ленина"
+
+func TestParseMappingDefault(t *testing.T) {
+	m, err := parseMapping(defaultMapping, strings.Split(cml2csvHeader, ";"))
+	assert.Nil(t, err)
+	assert.Equal(t, &mapping{sku: 0, path: 1, name: 2, unit: -1, quantity: 3}, m)
+
+	// columns besides name may be absent
+	m, err = parseMapping(defaultMapping, []string{"Наименование"})
+	assert.Nil(t, err)
+	assert.Equal(t, &mapping{sku: -1, path: -1, name: 0, unit: -1, quantity: -1}, m)
+
+	_, err = parseMapping(defaultMapping, []string{"Артикул"})
+	assert.NotNil(t, err)
+}
+
+func TestParseMapping(t *testing.T) {
+	spec := defaultMapping + `,"price:Розничная=Розничная, RUB",stock:Основной склад=Основной склад`
+	m, err := parseMapping(spec, strings.Split(cml2csvHeader, ";"))
+	assert.Nil(t, err)
+	assert.Equal(t, []target{{name: "Розничная", column: 4}}, m.prices)
+	assert.Equal(t, []target{{name: "Основной склад", column: 6}}, m.stocks)
+
+	_, err = parseMapping("name=Наименование,unit=Единица", strings.Split(cml2csvHeader, ";"))
+	assert.NotNil(t, err)
+	_, err = parseMapping("name=Наименование,color=Цвет", []string{"Наименование", "Цвет"})
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"io"
+	"log"
+	"os"
+
+	"github.com/sevkin/go-cml/json"
+	"github.com/sevkin/go-cml/xml"
+	"github.com/vrischmann/envconfig"
+)
+
+var (
+	conf struct {
+		// Stream reads NDJSON written by cml2json in stream mode
+		Stream bool `envconfig:"default=false"`
+	}
+)
+
+func main() {
+	err := envconfig.InitWithPrefix(&conf, "JSON2CML")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// file from argument or stdin
+	var r io.Reader = os.Stdin
+	if len(os.Args) > 1 {
+		f, err := os.Open(os.Args[1])
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer f.Close()
+		r = f
+	}
+
+	var x *xml.КоммерческаяИнформация
+	if conf.Stream {
+		x, err = json.ReadStream(r)
+	} else {
+		x, err = json.Read(r)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err := xml.Write(x, os.Stdout); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,20 @@
+// Package currency normalizes currency names used by 1C
+package currency
+
+import (
+	"strings"
+)
+
+// Code returns ISO 4217 code of currency name used by 1C (руб, RUR...)
+func Code(name string) string {
+	code := strings.ToUpper(strings.TrimSuffix(strings.TrimSpace(name), "."))
+	switch code {
+	case "РУБ", "RUR", "643":
+		return "RUB"
+	case "ДОЛЛ", "$", "840":
+		return "USD"
+	case "ЕВРО", "€", "978":
+		return "EUR"
+	}
+	return code
+}
@@ -0,0 +1,16 @@
+package currency
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCode(t *testing.T) {
+	for name, code := range map[string]string{
+		"руб": "RUB", "руб.": "RUB", "RUR": "RUB", "RUB": "RUB", "643": "RUB",
+		"usd": "USD", "ЕВРО": "EUR", "": "",
+	} {
+		assert.Equal(t, code, Code(name), name)
+	}
+}
@@ -0,0 +1,63 @@
+package json
+
+import (
+	"fmt"
+	"reflect"
+)
+
+var pkgPath = reflect.TypeOf(Document{}).PkgPath()
+
+// convert copies src to dst where one of them is the type of this package
+// and the other one is its xml counterpart. Fields are matched by cml tag,
+// pointers and *[]T are dereferenced, empty slices stay nil
+func convert(dst, src reflect.Value) {
+	if src.Kind() == reflect.Ptr {
+		if !src.IsNil() {
+			convert(dst, src.Elem())
+		}
+		return
+	}
+	if dst.Kind() == reflect.Ptr {
+		if src.Kind() == reflect.Slice && src.Len() == 0 {
+			return
+		}
+		v := reflect.New(dst.Type().Elem())
+		convert(v.Elem(), src)
+		dst.Set(v)
+		return
+	}
+
+	switch dst.Kind() {
+	case reflect.Struct:
+		latin, cml, toLatin := dst, src, true
+		if dst.Type().PkgPath() != pkgPath {
+			latin, cml, toLatin = src, dst, false
+		}
+		for idx := 0; idx < latin.NumField(); idx++ {
+			name := latin.Type().Field(idx).Tag.Get("cml")
+			field := cml.FieldByName(name)
+			if !field.IsValid() {
+				panic(fmt.Sprintf("json: %s has no field %s", cml.Type(), name))
+			}
+			if toLatin {
+				convert(latin.Field(idx), field)
+			} else {
+				convert(field, latin.Field(idx))
+			}
+		}
+	case reflect.Slice:
+		if src.Len() == 0 {
+			return
+		}
+		s := reflect.MakeSlice(dst.Type(), src.Len(), src.Len())
+		for idx := 0; idx < src.Len(); idx++ {
+			convert(s.Index(idx), src.Index(idx))
+		}
+		dst.Set(s)
+	default:
+		if src.Kind() != dst.Kind() {
+			panic(fmt.Sprintf("json: can not convert %s to %s", src.Type(), dst.Type()))
+		}
+		dst.Set(src.Convert(dst.Type()))
+	}
+}
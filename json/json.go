@@ -0,0 +1,143 @@
+// Package json converts CommerceML documents to JSON with latin field names
+// and back. The mapping of fields is documented by cml tags of types
+package json
+
+import (
+	"encoding/json"
+	"io"
+	"reflect"
+
+	"github.com/sevkin/go-cml/xml"
+)
+
+// Item is the line of NDJSON stream: document header first,
+// then product with its offers (including variants) per line.
+// Offers of unknown products follow without product
+type Item struct {
+	Document *Document `json:"document,omitempty"`
+	Product  *Product  `json:"product,omitempty"`
+	Offers   []Offer   `json:"offers,omitempty"`
+}
+
+// From converts x to Document
+func From(x *xml.КоммерческаяИнформация) *Document {
+	d := new(Document)
+	convert(reflect.ValueOf(d).Elem(), reflect.ValueOf(x).Elem())
+	return d
+}
+
+// CML converts d to КоммерческаяИнформация
+func (d *Document) CML() *xml.КоммерческаяИнформация {
+	x := new(xml.КоммерческаяИнформация)
+	convert(reflect.ValueOf(x).Elem(), reflect.ValueOf(d).Elem())
+	return x
+}
+
+func encoder(w io.Writer) *json.Encoder {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	return enc
+}
+
+// Write writes x to w as indented JSON
+func Write(x *xml.КоммерческаяИнформация, w io.Writer) error {
+	enc := encoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(From(x))
+}
+
+// Read reads JSON document from r
+func Read(r io.Reader) (*xml.КоммерческаяИнформация, error) {
+	d := new(Document)
+	if err := json.NewDecoder(r).Decode(d); err != nil {
+		return nil, err
+	}
+	return d.CML(), nil
+}
+
+// WriteStream writes x to w as NDJSON, one product with its offers per line
+func WriteStream(x *xml.КоммерческаяИнформация, w io.Writer) error {
+	header := *x
+	var products []xml.Товар
+	var offers []xml.Предложение
+	if x.Каталог != nil {
+		catalog := *x.Каталог
+		products, catalog.Товары = catalog.Товары, nil
+		header.Каталог = &catalog
+	}
+	if x.ПакетПредложений != nil {
+		pack := *x.ПакетПредложений
+		offers, pack.Предложения = pack.Предложения, nil
+		header.ПакетПредложений = &pack
+	}
+
+	enc := encoder(w)
+	if err := enc.Encode(&Item{Document: From(&header)}); err != nil {
+		return err
+	}
+
+	byProduct := make(map[string][]Offer)
+	for idx := range offers {
+		id := offers[idx].ProductID()
+		byProduct[id] = append(byProduct[id], *fromOffer(&offers[idx]))
+	}
+	for idx := range products {
+		p := new(Product)
+		convert(reflect.ValueOf(p).Elem(), reflect.ValueOf(&products[idx]).Elem())
+		if err := enc.Encode(&Item{Product: p, Offers: byProduct[p.ID]}); err != nil {
+			return err
+		}
+		delete(byProduct, p.ID)
+	}
+
+	// offers without product in order of document
+	var rest []Offer
+	for idx := range offers {
+		if _, found := byProduct[offers[idx].ProductID()]; found {
+			rest = append(rest, *fromOffer(&offers[idx]))
+		}
+	}
+	if len(rest) > 0 {
+		return enc.Encode(&Item{Offers: rest})
+	}
+	return nil
+}
+
+func fromOffer(o *xml.Предложение) *Offer {
+	res := new(Offer)
+	convert(reflect.ValueOf(res).Elem(), reflect.ValueOf(o).Elem())
+	return res
+}
+
+// ReadStream reads NDJSON written by WriteStream
+func ReadStream(r io.Reader) (*xml.КоммерческаяИнформация, error) {
+	d := new(Document)
+	dec := json.NewDecoder(r)
+	for {
+		item := new(Item)
+		err := dec.Decode(item)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if item.Document != nil {
+			d = item.Document
+		}
+		if item.Product != nil {
+			if d.Catalog == nil {
+				d.Catalog = new(Catalog)
+			}
+			d.Catalog.Products = append(d.Catalog.Products, *item.Product)
+		}
+		if len(item.Offers) > 0 {
+			if d.Offers == nil {
+				d.Offers = new(Offers)
+			}
+			d.Offers.Offers = append(d.Offers.Offers, item.Offers...)
+		}
+	}
+	return d.CML(), nil
+}
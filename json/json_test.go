@@ -0,0 +1,129 @@
+package json
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sevkin/go-cml/xml"
+)
+
+// fill sets every field reachable from v: strings to "x", bools to true,
+// pointers and slices get one element down to depth (groups are recursive)
+func fill(v reflect.Value, depth int) {
+	if depth < 3 && (v.Kind() == reflect.Ptr || v.Kind() == reflect.Slice) {
+		return
+	}
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString("x")
+	case reflect.Bool:
+		v.SetBool(true)
+	case reflect.Ptr:
+		v.Set(reflect.New(v.Type().Elem()))
+		fill(v.Elem(), depth-1)
+	case reflect.Slice:
+		v.Set(reflect.MakeSlice(v.Type(), 1, 1))
+		fill(v.Index(0), depth-1)
+	case reflect.Struct:
+		for idx := 0; idx < v.NumField(); idx++ {
+			fill(v.Field(idx), depth-1)
+		}
+	}
+}
+
+func TestMappingComplete(t *testing.T) {
+	x := new(xml.КоммерческаяИнформация)
+	fill(reflect.ValueOf(x).Elem(), 12)
+	// raw inner xml of Bitrix stock is not mapped
+	x.ПакетПредложений.Предложения[0].Склад[0].Склад = ""
+
+	assert.Equal(t, x, From(x).CML())
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, fname := range []string{"../xml/testdata/import.xml", "../xml/testdata/offers.xml",
+		"../xml/testdata/prices.xml", "../xml/testdata/rests.xml"} {
+		x := xml.ReadMust(fname)
+		for idx := range pack(x).Предложения {
+			for s := range pack(x).Предложения[idx].Склад {
+				pack(x).Предложения[idx].Склад[s].Склад = ""
+			}
+		}
+
+		var buf bytes.Buffer
+		assert.Nil(t, Write(x, &buf))
+		y, err := Read(&buf)
+		assert.Nil(t, err)
+		assert.Equal(t, x, y, fname)
+	}
+}
+
+func pack(x *xml.КоммерческаяИнформация) *xml.ПакетПредложений {
+	if x.ПакетПредложений == nil {
+		return new(xml.ПакетПредложений)
+	}
+	return x.ПакетПредложений
+}
+
+func TestWrite(t *testing.T) {
+	var buf bytes.Buffer
+	assert.Nil(t, Write(xml.ReadMust("../xml/testdata/import.xml"), &buf))
+	s := buf.String()
+	assert.Contains(t, s, `"article": "ПЛ-001"`)
+	assert.Contains(t, s, `"inn": "7701234567"`)
+	assert.Contains(t, s, `"full_name": "Пара (2 шт.)"`)
+	assert.Contains(t, s, `"Платье летнее \"Ромашка\", хлопок"`)
+	assert.NotContains(t, s, `"offers"`)
+}
+
+func document() *xml.КоммерческаяИнформация {
+	imp := xml.ReadMust("../xml/testdata/import.xml")
+	offers := xml.ReadMust("../xml/testdata/offers.xml")
+	x, _ := xml.Merge(imp, offers)
+	for idx := range x.ПакетПредложений.Предложения {
+		for s := range x.ПакетПредложений.Предложения[idx].Склад {
+			x.ПакетПредложений.Предложения[idx].Склад[s].Склад = ""
+		}
+	}
+	return x
+}
+
+func TestStream(t *testing.T) {
+	x := document()
+	x.ПакетПредложений.Предложения = append(x.ПакетПредложений.Предложения,
+		xml.Предложение{Ид: "unknown", Наименование: "Нет товара"})
+
+	var buf bytes.Buffer
+	assert.Nil(t, WriteStream(x, &buf))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Equal(t, 1+len(x.Каталог.Товары)+1, len(lines))
+	assert.True(t, strings.HasPrefix(lines[0], `{"document":`))
+	assert.NotContains(t, lines[0], `"products"`)
+	assert.True(t, strings.HasPrefix(lines[1], `{"product":{"id":"c5e4a1b0-3d2e-11e9-80d4-0cc47a7c2f11"`))
+	assert.Equal(t, 2, strings.Count(lines[1], `"characteristics"`))
+	assert.True(t, strings.HasPrefix(lines[5], `{"offers":[{"id":"unknown"`))
+
+	y, err := ReadStream(&buf)
+	assert.Nil(t, err)
+	assert.Equal(t, x.Каталог, y.Каталог)
+	assert.Equal(t, x.Классификатор, y.Классификатор)
+	// offers are grouped by product
+	assert.Equal(t, len(x.ПакетПредложений.Предложения), len(y.ПакетПредложений.Предложения))
+	assert.Equal(t, x.ПакетПредложений.ТипыЦен, y.ПакетПредложений.ТипыЦен)
+}
+
+func TestReadStreamErrors(t *testing.T) {
+	_, err := ReadStream(strings.NewReader(`{"document":`))
+	assert.NotNil(t, err)
+
+	x, err := ReadStream(strings.NewReader(`{"product":{"id":"p1","name":"Кеды"}}` + "\n" +
+		`{"offers":[{"id":"p1","name":"Кеды","quantity":"1"}]}`))
+	assert.Nil(t, err)
+	assert.Equal(t, "Кеды", x.Каталог.Товары[0].Наименование)
+	assert.Equal(t, "1", x.ПакетПредложений.Предложения[0].Количество)
+}
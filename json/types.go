@@ -0,0 +1,299 @@
+package json
+
+// Latin counterparts of xml types. Tag cml names the field of xml type
+// the value is converted from and to, tag json is the stable JSON name
+
+type (
+	// Document is КоммерческаяИнформация
+	Document struct {
+		Version      string        `json:"version" cml:"ВерсияСхемы"`
+		Date         string        `json:"date" cml:"ДатаФормирования"`
+		SyncProducts bool          `json:"sync_products,omitempty" cml:"СинхронизацияТоваров"`
+		Classifier   *Classifier   `json:"classifier,omitempty" cml:"Классификатор"`
+		Catalog      *Catalog      `json:"catalog,omitempty" cml:"Каталог"`
+		Offers       *Offers       `json:"offers,omitempty" cml:"ПакетПредложений"`
+		OffersChange *OffersChange `json:"offers_change,omitempty" cml:"ИзменениеПакетаПредложений"`
+		Orders       []Order       `json:"orders,omitempty" cml:"Документ"`
+	}
+
+	// Classifier is Классификатор
+	Classifier struct {
+		ID         string     `json:"id" cml:"Ид"`
+		Name       string     `json:"name" cml:"Наименование"`
+		Owner      Contractor `json:"owner" cml:"Владелец"`
+		Groups     []Group    `json:"groups,omitempty" cml:"Группы"`
+		Properties []Property `json:"properties,omitempty" cml:"Свойства"`
+	}
+
+	// Group is Группа
+	Group struct {
+		ID     string  `json:"id" cml:"Ид"`
+		Name   string  `json:"name" cml:"Наименование"`
+		Groups []Group `json:"groups,omitempty" cml:"Группы"`
+	}
+
+	// Property is Свойство
+	Property struct {
+		ID        string         `json:"id" cml:"Ид"`
+		Name      string         `json:"name" cml:"Наименование"`
+		ValueType string         `json:"value_type,omitempty" cml:"ТипЗначений"`
+		Values    []PropertyEnum `json:"values,omitempty" cml:"ВариантыЗначений"`
+	}
+
+	// PropertyEnum is ВариантЗначения
+	PropertyEnum struct {
+		ID    string `json:"id" cml:"ИдЗначения"`
+		Value string `json:"value" cml:"Значение"`
+	}
+
+	// Catalog is Каталог
+	Catalog struct {
+		ChangesOnly  bool      `json:"changes_only,omitempty" cml:"СодержитТолькоИзменения"`
+		ID           string    `json:"id" cml:"Ид"`
+		ClassifierID string    `json:"classifier_id" cml:"ИдКлассификатора"`
+		Name         string    `json:"name" cml:"Наименование"`
+		Products     []Product `json:"products,omitempty" cml:"Товары"`
+	}
+
+	// Product is Товар
+	Product struct {
+		Status       string          `json:"status,omitempty" cml:"Статус"`
+		ID           string          `json:"id" cml:"Ид"`
+		Deleted      bool            `json:"deleted,omitempty" cml:"ПометкаУдаления"`
+		Barcode      string          `json:"barcode,omitempty" cml:"Штрихкод"`
+		Article      string          `json:"article,omitempty" cml:"Артикул"`
+		Name         string          `json:"name" cml:"Наименование"`
+		Unit         Unit            `json:"unit" cml:"БазоваяЕдиница"`
+		Groups       []string        `json:"groups,omitempty" cml:"Группы"`
+		Description  string          `json:"description,omitempty" cml:"Описание"`
+		Images       []string        `json:"images,omitempty" cml:"Картинка"`
+		Country      string          `json:"country,omitempty" cml:"Страна"`
+		Manufacturer *Manufacturer   `json:"manufacturer,omitempty" cml:"Изготовитель"`
+		Properties   []PropertyValue `json:"properties,omitempty" cml:"ЗначенияСвойств"`
+		Taxes        []TaxRate       `json:"taxes,omitempty" cml:"СтавкиНалогов"`
+		Requisites   []Requisite     `json:"requisites,omitempty" cml:"ЗначенияРеквизитов"`
+	}
+
+	// Unit is БазоваяЕдиница
+	Unit struct {
+		Code          string       `json:"code,omitempty" cml:"Код"`
+		Name          string       `json:"name" cml:"БазоваяЕдиница"`
+		FullName      string       `json:"full_name,omitempty" cml:"НаименованиеПолное"`
+		International string       `json:"international,omitempty" cml:"МеждународноеСокращение"`
+		Conversions   []Conversion `json:"conversions,omitempty" cml:"Пересчет"`
+	}
+
+	// Conversion is Пересчет
+	Conversion struct {
+		Unit  string `json:"unit" cml:"Единица"`
+		Ratio string `json:"ratio" cml:"Коэффициент"`
+	}
+
+	// Manufacturer is Изготовитель
+	Manufacturer struct {
+		ID           string `json:"id,omitempty" cml:"Ид"`
+		Name         string `json:"name" cml:"Наименование"`
+		OfficialName string `json:"official_name,omitempty" cml:"ОфициальноеНаименование"`
+	}
+
+	// PropertyValue is ЗначенияСвойства
+	PropertyValue struct {
+		ID    string `json:"id" cml:"Ид"`
+		Value string `json:"value" cml:"Значение"`
+	}
+
+	// TaxRate is СтавкаНалога
+	TaxRate struct {
+		Name string `json:"name" cml:"Наименование"`
+		Rate string `json:"rate" cml:"Ставка"`
+	}
+
+	// Requisite is ЗначениеРеквизита
+	Requisite struct {
+		Name  string `json:"name" cml:"Наименование"`
+		Value string `json:"value" cml:"Значение"`
+	}
+
+	// Offers is ПакетПредложений
+	Offers struct {
+		ChangesOnly  bool        `json:"changes_only,omitempty" cml:"СодержитТолькоИзменения"`
+		ID           string      `json:"id" cml:"Ид"`
+		Name         string      `json:"name" cml:"Наименование"`
+		CatalogID    string      `json:"catalog_id" cml:"ИдКаталога"`
+		ClassifierID string      `json:"classifier_id,omitempty" cml:"ИдКлассификатора"`
+		Owner        Contractor  `json:"owner" cml:"Владелец"`
+		PriceTypes   []PriceType `json:"price_types,omitempty" cml:"ТипыЦен"`
+		Warehouses   []Warehouse `json:"warehouses,omitempty" cml:"Склады"`
+		Offers       []Offer     `json:"offers,omitempty" cml:"Предложения"`
+	}
+
+	// PriceType is ТипЦены
+	PriceType struct {
+		ID       string `json:"id" cml:"Ид"`
+		Name     string `json:"name" cml:"Наименование"`
+		Currency string `json:"currency" cml:"Валюта"`
+		Tax      Tax    `json:"tax" cml:"Налог"`
+	}
+
+	// Tax is Налог of ТипЦены
+	Tax struct {
+		Name     string `json:"name" cml:"Наименование"`
+		Included bool   `json:"included" cml:"УчтеноВСумме"`
+	}
+
+	// Warehouse is Склад
+	Warehouse struct {
+		ID   string `json:"id" cml:"Ид"`
+		Name string `json:"name" cml:"Наименование"`
+	}
+
+	// Offer is Предложение
+	Offer struct {
+		ID              string           `json:"id" cml:"Ид"`
+		Article         string           `json:"article,omitempty" cml:"Артикул"`
+		Name            string           `json:"name" cml:"Наименование"`
+		Unit            Unit             `json:"unit" cml:"БазоваяЕдиница"`
+		Characteristics []Characteristic `json:"characteristics,omitempty" cml:"ХарактеристикиТовара"`
+		Prices          []Price          `json:"prices,omitempty" cml:"Цены"`
+		Quantity        string           `json:"quantity,omitempty" cml:"Количество"`
+		Stock           []Stock          `json:"stock,omitempty" cml:"Склад"`
+	}
+
+	// Characteristic is ХарактеристикаТовара
+	Characteristic struct {
+		ID    string `json:"id,omitempty" cml:"Ид"`
+		Name  string `json:"name" cml:"Наименование"`
+		Value string `json:"value" cml:"Значение"`
+	}
+
+	// Price is Цена
+	Price struct {
+		TypeID   string `json:"type_id" cml:"ИдТипаЦены"`
+		Value    string `json:"value" cml:"ЦенаЗаЕдиницу"`
+		Currency string `json:"currency,omitempty" cml:"Валюта"`
+		Unit     string `json:"unit,omitempty" cml:"Единица"`
+		Ratio    string `json:"ratio,omitempty" cml:"Коэффициент"`
+	}
+
+	// Stock is Остаток
+	Stock struct {
+		WarehouseID string `json:"warehouse_id" cml:"ИдСклада"`
+		Quantity    string `json:"quantity" cml:"КоличествоНаСкладе"`
+	}
+
+	// OffersChange is ИзменениеПакетаПредложений of CommerceML 3.x
+	OffersChange struct {
+		ChangesOnly  bool          `json:"changes_only,omitempty" cml:"СодержитТолькоИзменения"`
+		ID           string        `json:"id" cml:"Ид"`
+		Name         string        `json:"name" cml:"Наименование"`
+		CatalogID    string        `json:"catalog_id" cml:"ИдКаталога"`
+		ClassifierID string        `json:"classifier_id,omitempty" cml:"ИдКлассификатора"`
+		Offers       []OfferChange `json:"offers,omitempty" cml:"Предложения"`
+	}
+
+	// OfferChange is ИзменениеПредложения
+	OfferChange struct {
+		ID     string  `json:"id" cml:"Ид"`
+		Prices []Price `json:"prices,omitempty" cml:"Цены"`
+		Rests  []Rest  `json:"rests,omitempty" cml:"Остатки"`
+	}
+
+	// Rest is ОстатокТовара
+	Rest struct {
+		Warehouse *WarehouseRest `json:"warehouse,omitempty" cml:"Склад"`
+		Quantity  string         `json:"quantity,omitempty" cml:"Количество"`
+	}
+
+	// WarehouseRest is ОстатокНаСкладе
+	WarehouseRest struct {
+		ID       string `json:"id" cml:"Ид"`
+		Quantity string `json:"quantity" cml:"Количество"`
+	}
+
+	// Contractor is Контрагент
+	Contractor struct {
+		ID              string           `json:"id" cml:"Ид"`
+		Name            string           `json:"name" cml:"Наименование"`
+		Role            string           `json:"role,omitempty" cml:"Роль"`
+		FullName        string           `json:"full_name,omitempty" cml:"ПолноеНаименование"`
+		OfficialName    string           `json:"official_name,omitempty" cml:"ОфициальноеНаименование"`
+		LegalAddress    *Address         `json:"legal_address,omitempty" cml:"ЮридическийАдрес"`
+		INN             string           `json:"inn,omitempty" cml:"ИНН"`
+		KPP             string           `json:"kpp,omitempty" cml:"КПП"`
+		OKPO            string           `json:"okpo,omitempty" cml:"ОКПО"`
+		Accounts        []Account        `json:"accounts,omitempty" cml:"РасчетныеСчета"`
+		Address         *Address         `json:"address,omitempty" cml:"Адрес"`
+		Contacts        []Contact        `json:"contacts,omitempty" cml:"Контакты"`
+		Representatives []Representative `json:"representatives,omitempty" cml:"Представители"`
+	}
+
+	// Address is Адрес
+	Address struct {
+		Text   string         `json:"text,omitempty" cml:"Представление"`
+		Fields []AddressField `json:"fields,omitempty" cml:"АдресноеПоле"`
+	}
+
+	// AddressField is АдресноеПоле
+	AddressField struct {
+		Type  string `json:"type" cml:"Тип"`
+		Value string `json:"value" cml:"Значение"`
+	}
+
+	// Contact is Контакт
+	Contact struct {
+		Type    string `json:"type" cml:"Тип"`
+		Value   string `json:"value" cml:"Значение"`
+		Comment string `json:"comment,omitempty" cml:"Комментарий"`
+	}
+
+	// Account is РасчетныйСчет
+	Account struct {
+		Number  string `json:"number" cml:"НомерСчета"`
+		Bank    *Bank  `json:"bank,omitempty" cml:"Банк"`
+		Comment string `json:"comment,omitempty" cml:"Комментарий"`
+	}
+
+	// Bank is Банк
+	Bank struct {
+		Name                 string   `json:"name" cml:"Наименование"`
+		CorrespondentAccount string   `json:"correspondent_account,omitempty" cml:"СчетКорреспондентский"`
+		Address              *Address `json:"address,omitempty" cml:"Адрес"`
+		BIC                  string   `json:"bic,omitempty" cml:"БИК"`
+	}
+
+	// Representative is Представитель
+	Representative struct {
+		Relation string `json:"relation" cml:"Отношение"`
+		ID       string `json:"id" cml:"Ид"`
+		Name     string `json:"name" cml:"Наименование"`
+	}
+
+	// Order is Документ
+	Order struct {
+		ID          string       `json:"id" cml:"Ид"`
+		Number      string       `json:"number" cml:"Номер"`
+		Date        string       `json:"date" cml:"Дата"`
+		Operation   string       `json:"operation" cml:"ХозОперация"`
+		Role        string       `json:"role" cml:"Роль"`
+		Currency    string       `json:"currency" cml:"Валюта"`
+		Rate        string       `json:"rate,omitempty" cml:"Курс"`
+		Sum         string       `json:"sum" cml:"Сумма"`
+		Contractors []Contractor `json:"contractors,omitempty" cml:"Контрагенты"`
+		Time        string       `json:"time,omitempty" cml:"Время"`
+		Comment     string       `json:"comment,omitempty" cml:"Комментарий"`
+		Items       []OrderItem  `json:"items,omitempty" cml:"Товары"`
+		Requisites  []Requisite  `json:"requisites,omitempty" cml:"ЗначенияРеквизитов"`
+	}
+
+	// OrderItem is ТоварДокумента
+	OrderItem struct {
+		ID         string      `json:"id" cml:"Ид"`
+		Article    string      `json:"article,omitempty" cml:"Артикул"`
+		Name       string      `json:"name" cml:"Наименование"`
+		Unit       *Unit       `json:"unit,omitempty" cml:"БазоваяЕдиница"`
+		Price      string      `json:"price,omitempty" cml:"ЦенаЗаЕдиницу"`
+		Quantity   string      `json:"quantity" cml:"Количество"`
+		Sum        string      `json:"sum" cml:"Сумма"`
+		Requisites []Requisite `json:"requisites,omitempty" cml:"ЗначенияРеквизитов"`
+	}
+)
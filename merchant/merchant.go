@@ -0,0 +1,240 @@
+// Package merchant converts CommerceML catalog to Google Merchant Center product feed
+// (RSS 2.0 with g: namespace) https://support.google.com/merchants/answer/7052112
+package merchant
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/xml"
+	"io"
+	"strings"
+	"text/template"
+	"unicode/utf8"
+
+	"github.com/sevkin/go-cml/catalog"
+)
+
+// Namespace of product attributes
+const Namespace = "http://base.google.com/ns/1.0"
+
+type (
+	// RSS is the root element of feed
+	RSS struct {
+		XMLName xml.Name `xml:"rss"`
+		Version string   `xml:"version,attr"`
+		G       string   `xml:"xmlns:g,attr"`
+		Channel Channel  `xml:"channel"`
+		// Skipped are Ид of offers not exported with reason
+		Skipped []string `xml:"-"`
+	}
+
+	// Channel describes shop and its items
+	Channel struct {
+		Title       string `xml:"title"`
+		Link        string `xml:"link"`
+		Description string `xml:"description"`
+		Items       []Item `xml:"item"`
+	}
+
+	// Item is the product or its variant.
+	// Tags carry g: prefix literally since encoding/xml can`t declare prefixes
+	Item struct {
+		ID                   string   `xml:"g:id"`
+		Title                string   `xml:"g:title"`
+		Description          string   `xml:"g:description,omitempty"`
+		Link                 string   `xml:"g:link,omitempty"`
+		ImageLink            string   `xml:"g:image_link,omitempty"`
+		AdditionalImageLinks []string `xml:"g:additional_image_link,omitempty"`
+		Availability         string   `xml:"g:availability"`
+		Price                string   `xml:"g:price"`
+		Brand                string   `xml:"g:brand,omitempty"`
+		GTIN                 string   `xml:"g:gtin,omitempty"`
+		MPN                  string   `xml:"g:mpn,omitempty"`
+		Condition            string   `xml:"g:condition"`
+		ItemGroupID          string   `xml:"g:item_group_id,omitempty"`
+		ProductType          string   `xml:"g:product_type,omitempty"`
+	}
+
+	// Image is the data of ImageLink template
+	Image struct {
+		*catalog.Offer
+		Path string // Картинка, import_files/ab/abcd.jpg
+	}
+
+	// Options of conversion
+	Options struct {
+		Title       string
+		Link        string
+		Description string
+		// PriceType is Ид or Наименование of ТипЦены, the first one if empty
+		PriceType string
+		// Warehouses are Ид or Наименование of Склад to sum stock on,
+		// Quantity of offer (Количество or all stock) is used if empty
+		Warehouses []string
+		// ItemLink is text/template of item link executed on *catalog.Offer
+		ItemLink string
+		// ImageLink is text/template of image link executed on Image, {{.Path}} if empty
+		ImageLink string
+	}
+)
+
+// Availability values
+const (
+	InStock    = "in_stock"
+	OutOfStock = "out_of_stock"
+)
+
+func execute(t *template.Template, data interface{}) (string, error) {
+	if t == nil {
+		return "", nil
+	}
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// article returns Артикул of offer or its product
+func article(o *catalog.Offer) string {
+	if len(o.Артикул) > 0 {
+		return o.Артикул
+	}
+	if o.Product != nil {
+		return o.Product.Артикул
+	}
+	return ""
+}
+
+// maxID is the limit of g:id length
+const maxID = 50
+
+// itemID returns id of offer Ид fitting into g:id,
+// Ид of variants (uuid#uuid) is replaced by its hash
+func itemID(id string) string {
+	if utf8.RuneCountInString(id) <= maxID {
+		return id
+	}
+	sum := sha1.Sum([]byte(id))
+	return hex.EncodeToString(sum[:])
+}
+
+// Convert returns feed of c.
+// Item id is Артикул if it is unique among offers, Ид or its hash otherwise
+func Convert(c *catalog.Catalog, opt Options) (*RSS, error) {
+	sel, err := c.Select(opt.PriceType, opt.Warehouses)
+	if err != nil {
+		return nil, err
+	}
+
+	var itemLink *template.Template
+	if len(opt.ItemLink) > 0 {
+		t, err := template.New("link").Parse(opt.ItemLink)
+		if err != nil {
+			return nil, err
+		}
+		itemLink = t
+	}
+	if len(opt.ImageLink) == 0 {
+		opt.ImageLink = "{{.Path}}"
+	}
+	imageLink, err := template.New("image").Parse(opt.ImageLink)
+	if err != nil {
+		return nil, err
+	}
+
+	articles := make(map[string]int)
+	for _, o := range c.Offers() {
+		articles[article(o)]++
+	}
+
+	rss := &RSS{
+		Version: "2.0",
+		G:       Namespace,
+		Channel: Channel{Title: opt.Title, Link: opt.Link, Description: opt.Description},
+	}
+
+	for _, o := range c.Offers() {
+		p := o.Product
+		switch {
+		case p == nil:
+			rss.Skipped = append(rss.Skipped, o.Ид+": unknown product")
+			continue
+		case p.Deleted():
+			rss.Skipped = append(rss.Skipped, o.Ид+": product deleted")
+			continue
+		}
+		price := sel.Price(o)
+		if price == nil {
+			rss.Skipped = append(rss.Skipped, o.Ид+": no price")
+			continue
+		}
+
+		amount := strings.Replace(price.ЦенаЗаЕдиницу, ",", ".", 1)
+		if currency := sel.Currency(price); len(currency) > 0 {
+			amount += " " + currency
+		}
+
+		item := Item{
+			ID:           itemID(o.Ид),
+			Title:        p.Наименование,
+			Description:  p.Описание,
+			Availability: OutOfStock,
+			Price:        amount,
+			GTIN:         p.Штрихкод,
+			MPN:          article(o),
+			Condition:    "new",
+		}
+		if a := article(o); len(a) > 0 && articles[a] == 1 {
+			item.ID = a
+		}
+		if sel.Available(o) {
+			item.Availability = InStock
+		}
+		if p.Изготовитель != nil {
+			item.Brand = p.Изготовитель.Наименование
+		}
+		if o.Variant() {
+			item.ItemGroupID = p.Ид
+			if len(o.Наименование) > 0 {
+				item.Title = o.Наименование
+			}
+		}
+		if len(p.Groups) > 0 {
+			item.ProductType = strings.Join(p.Groups[0].Path, " > ")
+		}
+		if item.Link, err = execute(itemLink, o); err != nil {
+			return nil, err
+		}
+		for idx, path := range p.Картинка {
+			link, err := execute(imageLink, Image{Offer: o, Path: path})
+			if err != nil {
+				return nil, err
+			}
+			if idx == 0 {
+				item.ImageLink = link
+			} else {
+				item.AdditionalImageLinks = append(item.AdditionalImageLinks, link)
+			}
+		}
+
+		rss.Channel.Items = append(rss.Channel.Items, item)
+	}
+
+	return rss, nil
+}
+
+// Write writes rss as XML document to w
+func Write(rss *RSS, w io.Writer) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(rss); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
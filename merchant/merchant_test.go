@@ -0,0 +1,111 @@
+package merchant
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sevkin/go-cml/catalog"
+)
+
+func load(t *testing.T) *catalog.Catalog {
+	c, err := catalog.LoadFiles("../xml/testdata/import.xml", "../xml/testdata/offers.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestConvert(t *testing.T) {
+	rss, err := Convert(load(t), Options{
+		Title:     "Shop",
+		ItemLink:  "https://example.com/p/{{.Product.Ид}}",
+		ImageLink: "https://example.com/upload/{{.Path}}",
+	})
+	assert.Nil(t, err)
+	items := rss.Channel.Items
+	assert.Equal(t, 4, len(items))
+
+	// variants share Артикул
+	assert.Equal(t, itemID("c5e4a1b0-3d2e-11e9-80d4-0cc47a7c2f11#8a9b0c1d-6e7f-11e9-80d4-0cc47a7c2f11"), items[0].ID)
+	for _, item := range items {
+		assert.True(t, len(item.ID) <= maxID, item.ID)
+	}
+	assert.NotEqual(t, items[0].ID, items[1].ID)
+	assert.Equal(t, "c5e4a1b0-3d2e-11e9-80d4-0cc47a7c2f11", items[0].ItemGroupID)
+	assert.Equal(t, "ПЛ-001", items[0].MPN)
+	assert.Equal(t, InStock, items[0].Availability)
+	assert.Equal(t, OutOfStock, items[1].Availability)
+	assert.Equal(t, "2990 RUB", items[0].Price)
+	assert.Equal(t, "https://example.com/p/c5e4a1b0-3d2e-11e9-80d4-0cc47a7c2f11", items[0].Link)
+	assert.Equal(t, "https://example.com/upload/import_files/c5/c5e4a1b03d2e11e980d40cc47a7c2f11_a1.jpg", items[0].ImageLink)
+	assert.Equal(t, 1, len(items[0].AdditionalImageLinks))
+	assert.Equal(t, "Одежда > Платья", items[0].ProductType)
+
+	assert.Equal(t, "БР-014", items[2].ID)
+	assert.Equal(t, "", items[2].ItemGroupID)
+	assert.Equal(t, "3450.50 RUB", items[2].Price)
+}
+
+func TestConvertOptions(t *testing.T) {
+	c := load(t)
+	rss, err := Convert(c, Options{PriceType: "Оптовая", Warehouses: []string{"Магазин на Ленина"}})
+	assert.Nil(t, err)
+	for _, item := range rss.Channel.Items {
+		assert.Equal(t, item.MPN == "ПЛ-001", item.Availability == InStock, item.ID)
+		assert.Equal(t, "", item.Link)
+	}
+	assert.NotEmpty(t, rss.Skipped)
+
+	_, err = Convert(c, Options{PriceType: "Закупочная"})
+	assert.NotNil(t, err)
+	_, err = Convert(c, Options{Warehouses: []string{"Склад"}})
+	assert.NotNil(t, err)
+	_, err = Convert(c, Options{ItemLink: "{{"})
+	assert.NotNil(t, err)
+	_, err = Convert(c, Options{ImageLink: "{{.Unknown}}"})
+	assert.NotNil(t, err)
+}
+
+func TestConvertNoCurrency(t *testing.T) {
+	data, err := ioutil.ReadFile("../xml/testdata/offers.xml")
+	assert.Nil(t, err)
+	dir, err := ioutil.TempDir("", "merchant")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	fname := filepath.Join(dir, "offers.xml")
+	data = []byte(strings.Replace(string(data), "<Валюта>RUB</Валюта>", "", -1))
+	assert.Nil(t, ioutil.WriteFile(fname, data, 0644))
+
+	c, err := catalog.LoadFiles("../xml/testdata/import.xml", fname)
+	assert.Nil(t, err)
+	rss, err := Convert(c, Options{})
+	assert.Nil(t, err)
+	assert.Equal(t, "2990", rss.Channel.Items[0].Price)
+}
+
+func TestWrite(t *testing.T) {
+	rss, err := Convert(load(t), Options{Title: "Shop"})
+	assert.Nil(t, err)
+
+	var buf bytes.Buffer
+	assert.Nil(t, Write(rss, &buf))
+
+	// prefixed names must resolve to Google namespace
+	var back struct {
+		Items []struct {
+			ID    string `xml:"http://base.google.com/ns/1.0 id"`
+			Price string `xml:"http://base.google.com/ns/1.0 price"`
+		} `xml:"channel>item"`
+	}
+	assert.Nil(t, xml.Unmarshal(buf.Bytes(), &back))
+	assert.Equal(t, len(rss.Channel.Items), len(back.Items))
+	assert.Equal(t, rss.Channel.Items[3].ID, back.Items[3].ID)
+	assert.Equal(t, rss.Channel.Items[3].Price, back.Items[3].Price)
+}
@@ -0,0 +1,103 @@
+// Package okei is registry of units of measure of ОКЕИ (ОК 015-94)
+// used by 1C in БазоваяЕдиница and Единица of Цена
+package okei
+
+import (
+	"strings"
+)
+
+// Unit of measure
+type Unit struct {
+	// Code is numeric code of ОКЕИ (796)
+	Code string
+	// Name is national symbol (шт)
+	Name string
+	// FullName is the name of unit (Штука)
+	FullName string
+	// International is international code letters (PCE)
+	International string
+}
+
+// Units of registry, most used by 1C
+var Units = []Unit{
+	{"003", "мм", "Миллиметр", "MMT"},
+	{"004", "см", "Сантиметр", "CMT"},
+	{"006", "м", "Метр", "MTR"},
+	{"055", "м2", "Квадратный метр", "MTK"},
+	{"111", "мл", "Миллилитр", "MLT"},
+	{"112", "л", "Литр", "LTR"},
+	{"113", "м3", "Кубический метр", "MTQ"},
+	{"163", "г", "Грамм", "GRM"},
+	{"166", "кг", "Килограмм", "KGM"},
+	{"168", "т", "Тонна", "TNE"},
+	{"356", "ч", "Час", "HUR"},
+	{"642", "ед", "Единица", ""},
+	{"704", "набор", "Набор", "SET"},
+	{"715", "пар", "Пара (2 шт.)", "NPR"},
+	{"736", "рул", "Рулон", "NPL"},
+	{"778", "упак", "Упаковка", "NMP"},
+	{"796", "шт", "Штука", "PCE"},
+	{"798", "тыс. шт", "Тысяча штук", "MIL"},
+	{"839", "компл", "Комплект", ""},
+}
+
+var (
+	byCode = make(map[string]int, len(Units))
+	byName = make(map[string]int, 3*len(Units))
+)
+
+func init() {
+	for idx, u := range Units {
+		byCode[u.Code] = idx
+		for _, name := range []string{u.Name, u.FullName, u.International} {
+			if key := normalize(name); len(key) > 0 {
+				byName[key] = idx
+			}
+		}
+	}
+}
+
+// normalize lowers name and drops trailing dot of abbreviation (шт.)
+func normalize(name string) string {
+	return strings.ToLower(strings.TrimSuffix(strings.TrimSpace(name), "."))
+}
+
+// code pads numeric code with zeros: 6 is 006
+func code(s string) string {
+	s = strings.TrimSpace(s)
+	if len(s) == 0 || len(s) >= 3 || strings.Trim(s, "0123456789") != "" {
+		return s
+	}
+	return strings.Repeat("0", 3-len(s)) + s
+}
+
+// Lookup returns unit by numeric code
+func Lookup(c string) (Unit, bool) {
+	if idx, found := byCode[code(c)]; found {
+		return Units[idx], true
+	}
+	return Unit{}, false
+}
+
+// Find returns unit by code, national symbol, full name or international code
+// ignoring case and trailing dot
+func Find(name string) (Unit, bool) {
+	if u, found := Lookup(name); found {
+		return u, true
+	}
+	if idx, found := byName[normalize(name)]; found {
+		return Units[idx], true
+	}
+	return Unit{}, false
+}
+
+// Same reports whether names denote the same unit,
+// units unknown to registry are compared by name
+func Same(a, b string) bool {
+	ua, okA := Find(a)
+	ub, okB := Find(b)
+	if okA && okB {
+		return ua.Code == ub.Code
+	}
+	return len(normalize(a)) > 0 && normalize(a) == normalize(b)
+}
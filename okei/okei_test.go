@@ -0,0 +1,56 @@
+package okei
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLookup(t *testing.T) {
+	u, found := Lookup("796")
+	assert.True(t, found)
+	assert.Equal(t, Unit{"796", "шт", "Штука", "PCE"}, u)
+
+	u, found = Lookup("6")
+	assert.True(t, found)
+	assert.Equal(t, "м", u.Name)
+
+	_, found = Lookup("999")
+	assert.False(t, found)
+}
+
+func TestFind(t *testing.T) {
+	for _, name := range []string{"166", "кг", "КГ.", "Килограмм", "kgm", " кг "} {
+		u, found := Find(name)
+		assert.True(t, found, name)
+		assert.Equal(t, "166", u.Code, name)
+	}
+	_, found := Find("")
+	assert.False(t, found)
+	_, found = Find("бочка")
+	assert.False(t, found)
+}
+
+func TestSame(t *testing.T) {
+	assert.True(t, Same("шт", "796"))
+	assert.True(t, Same("шт.", "PCE"))
+	assert.False(t, Same("шт", "кг"))
+	assert.True(t, Same("бочка", "Бочка"))
+	assert.False(t, Same("бочка", "шт"))
+	assert.False(t, Same("", ""))
+}
+
+func TestRegistry(t *testing.T) {
+	// codes and names do not denote different units
+	seen := make(map[string]string)
+	for _, u := range Units {
+		for _, key := range []string{u.Code, normalize(u.Name), normalize(u.FullName), normalize(u.International)} {
+			if code, found := seen[key]; found {
+				assert.Equal(t, u.Code, code, key)
+			}
+			if len(key) > 0 {
+				seen[key] = u.Code
+			}
+		}
+	}
+}
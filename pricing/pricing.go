@@ -0,0 +1,174 @@
+// Package pricing computes prices of offers by ТипЦены:
+// without and with VAT, in another currency, rounded per currency rules
+package pricing
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/sevkin/go-cml/currency"
+	"github.com/sevkin/go-cml/xml"
+)
+
+type (
+	// Rates converts currencies given by ISO 4217 codes
+	Rates interface {
+		// Rate returns units of to per one unit of from
+		Rate(from, to string) (float64, error)
+	}
+
+	// Table of rates to Base currency: Rates["USD"] = 64.5 is 64.5 Base per 1 USD
+	Table struct {
+		Base  string
+		Rates map[string]float64
+	}
+
+	// Calculator of prices
+	Calculator struct {
+		// Currency of result, empty keeps currency of price
+		Currency string
+		// Rates used when currencies differ, may be nil if they never do
+		Rates Rates
+	}
+
+	// Price of offer
+	Price struct {
+		Net      float64 // without VAT
+		Gross    float64 // with VAT
+		VAT      float64 // rate of tax of price type, percent
+		Currency string  // ISO 4217 code
+	}
+)
+
+// ErrNoPrice is returned for offer without price of type
+var ErrNoPrice = errors.New("pricing: no price of type")
+
+// MinorUnits are decimals of currencies, 2 for the others
+var MinorUnits = map[string]int{
+	"BHD": 3,
+	"CLP": 0,
+	"IQD": 3,
+	"ISK": 0,
+	"JOD": 3,
+	"JPY": 0,
+	"KRW": 0,
+	"KWD": 3,
+	"LYD": 3,
+	"OMR": 3,
+	"TND": 3,
+	"VND": 0,
+}
+
+// Rate implements Rates
+func (t Table) Rate(from, to string) (float64, error) {
+	if from == to {
+		return 1, nil
+	}
+	rate := func(code string) (float64, error) {
+		if code == t.Base {
+			return 1, nil
+		}
+		if r, found := t.Rates[code]; found && r > 0 {
+			return r, nil
+		}
+		return 0, fmt.Errorf("pricing: no rate of %s to %s", code, t.Base)
+	}
+	f, err := rate(from)
+	if err != nil {
+		return 0, err
+	}
+	r, err := rate(to)
+	if err != nil {
+		return 0, err
+	}
+	return f / r, nil
+}
+
+// Round rounds value half away from zero to decimals of currency
+func Round(value float64, currency string) float64 {
+	decimals, found := MinorUnits[currency]
+	if !found {
+		decimals = 2
+	}
+	pow := math.Pow10(decimals)
+	// binary noise of 2.675 (2.67499999...) must not round it down
+	scaled, err := strconv.ParseFloat(strconv.FormatFloat(value*pow, 'f', 6, 64), 64)
+	if err != nil {
+		scaled = value * pow
+	}
+	return math.Round(scaled) / pow
+}
+
+// TaxRate parses Ставка of СтавкаНалога: 20, 10%, 0, Без НДС
+func TaxRate(s string) (float64, error) {
+	s = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(s), "%"))
+	if len(s) == 0 || strings.EqualFold(s, "Без НДС") {
+		return 0, nil
+	}
+	rate, err := strconv.ParseFloat(strings.Replace(s, ",", ".", 1), 64)
+	if err != nil || rate < 0 {
+		return 0, fmt.Errorf("pricing: bad tax rate %q", s)
+	}
+	return rate, nil
+}
+
+// Price returns price of offer o by type t. Rate of tax named by Налог of t
+// is taken from СтавкиНалогов of product p (no tax if p is nil),
+// Налог.УчтеноВСумме tells whether ЦенаЗаЕдиницу includes it.
+// Price of type without Налог is final, its Net and Gross are equal.
+// Currency of price is Валюта of Цена or of ТипЦены
+func (c *Calculator) Price(o *xml.Предложение, p *xml.Товар, t *xml.ТипЦены) (Price, error) {
+	var price *xml.Цена
+	for idx := range o.Цены {
+		if o.Цены[idx].ИдТипаЦены == t.Ид {
+			price = &o.Цены[idx]
+			break
+		}
+	}
+	if price == nil || len(strings.TrimSpace(price.ЦенаЗаЕдиницу)) == 0 {
+		return Price{}, ErrNoPrice
+	}
+	value, err := strconv.ParseFloat(strings.Replace(strings.TrimSpace(price.ЦенаЗаЕдиницу), ",", ".", 1), 64)
+	if err != nil {
+		return Price{}, fmt.Errorf("pricing: offer %s: bad price %q", o.Ид, price.ЦенаЗаЕдиницу)
+	}
+
+	var rate float64
+	if tax := t.Налог.Наименование; p != nil && len(tax) > 0 {
+		if s, found := p.Tax(tax); found {
+			if rate, err = TaxRate(s); err != nil {
+				return Price{}, fmt.Errorf("pricing: product %s: %v", p.Ид, err)
+			}
+		}
+	}
+
+	code := price.Валюта
+	if len(code) == 0 {
+		code = t.Валюта
+	}
+	code = currency.Code(code)
+	if target := currency.Code(c.Currency); len(target) > 0 && target != code {
+		if c.Rates == nil {
+			return Price{}, fmt.Errorf("pricing: no rates to convert %s to %s", code, target)
+		}
+		r, err := c.Rates.Rate(code, target)
+		if err != nil {
+			return Price{}, err
+		}
+		value *= r
+		code = target
+	}
+
+	result := Price{Net: value, Gross: value, VAT: rate, Currency: code}
+	if t.Налог.УчтеноВСумме {
+		result.Net = value / (1 + rate/100)
+	} else {
+		result.Gross = value * (1 + rate/100)
+	}
+	result.Net = Round(result.Net, code)
+	result.Gross = Round(result.Gross, code)
+	return result, nil
+}
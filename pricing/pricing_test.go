@@ -0,0 +1,157 @@
+package pricing
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sevkin/go-cml/xml"
+)
+
+func priceType(included bool, currency string) *xml.ТипЦены {
+	t := &xml.ТипЦены{Ид: "retail", Валюта: currency}
+	t.Налог.Наименование = "НДС"
+	t.Налог.УчтеноВСумме = included
+	return t
+}
+
+func taxed(name string, included bool) *xml.ТипЦены {
+	t := priceType(included, "RUB")
+	t.Налог.Наименование = name
+	return t
+}
+
+func TestPrice(t *testing.T) {
+	rates := Table{Base: "RUB", Rates: map[string]float64{"USD": 64, "EUR": 72, "JPY": 0.6}}
+	product := func(rate string) *xml.Товар {
+		return &xml.Товар{Ид: "p", СтавкиНалогов: &[]xml.СтавкаНалога{
+			{Наименование: "НСП", Ставка: "5"}, {Наименование: "НДС", Ставка: rate}}}
+	}
+	offer := func(value, currency string) *xml.Предложение {
+		return &xml.Предложение{Ид: "o", Цены: []xml.Цена{
+			{ИдТипаЦены: "wholesale", ЦенаЗаЕдиницу: "1"},
+			{ИдТипаЦены: "retail", ЦенаЗаЕдиницу: value, Валюта: currency},
+		}}
+	}
+
+	for _, c := range []struct {
+		name     string
+		calc     Calculator
+		offer    *xml.Предложение
+		product  *xml.Товар
+		typ      *xml.ТипЦены
+		expected Price
+		err      bool
+	}{
+		{"included", Calculator{}, offer("120", ""), product("20"), priceType(true, "RUB"),
+			Price{Net: 100, Gross: 120, VAT: 20, Currency: "RUB"}, false},
+		{"excluded", Calculator{}, offer("100", ""), product("20"), priceType(false, "руб"),
+			Price{Net: 100, Gross: 120, VAT: 20, Currency: "RUB"}, false},
+		{"rounded", Calculator{}, offer("99,99", ""), product("10%"), priceType(true, "RUB"),
+			Price{Net: 90.9, Gross: 99.99, VAT: 10, Currency: "RUB"}, false},
+		{"without VAT", Calculator{}, offer("50", ""), product("Без НДС"), priceType(false, "RUB"),
+			Price{Net: 50, Gross: 50, Currency: "RUB"}, false},
+		{"no product", Calculator{}, offer("50", ""), nil, priceType(true, "RUB"),
+			Price{Net: 50, Gross: 50, Currency: "RUB"}, false},
+		{"no tax", Calculator{}, offer("50", ""), &xml.Товар{}, priceType(false, "RUB"),
+			Price{Net: 50, Gross: 50, Currency: "RUB"}, false},
+		{"price currency", Calculator{}, offer("10", "USD"), product("0"), priceType(true, "RUB"),
+			Price{Net: 10, Gross: 10, Currency: "USD"}, false},
+		{"to base", Calculator{Currency: "RUB", Rates: rates}, offer("10.5", ""), product("20"), priceType(false, "USD"),
+			Price{Net: 672, Gross: 806.4, VAT: 20, Currency: "RUB"}, false},
+		{"cross rate", Calculator{Currency: "EUR", Rates: rates}, offer("100", ""), product("0"), priceType(true, "USD"),
+			Price{Net: 88.89, Gross: 88.89, Currency: "EUR"}, false},
+		{"no decimals", Calculator{Currency: "JPY", Rates: rates}, offer("100", ""), product("20"), priceType(true, "RUB"),
+			Price{Net: 139, Gross: 167, VAT: 20, Currency: "JPY"}, false},
+		{"other tax", Calculator{}, offer("100", ""), product("20"), taxed("НСП", false),
+			Price{Net: 100, Gross: 105, VAT: 5, Currency: "RUB"}, false},
+		{"unknown tax", Calculator{}, offer("100", ""), product("20"), taxed("Акциз", false),
+			Price{Net: 100, Gross: 100, Currency: "RUB"}, false},
+		{"no Налог", Calculator{}, offer("100", ""), product("20"), &xml.ТипЦены{Ид: "retail", Валюта: "RUB"},
+			Price{Net: 100, Gross: 100, Currency: "RUB"}, false},
+		{"same currency", Calculator{Currency: "RUR"}, offer("10", ""), product("0"), priceType(true, "RUB"),
+			Price{Net: 10, Gross: 10, Currency: "RUB"}, false},
+		{"no rates", Calculator{Currency: "USD"}, offer("10", ""), product("0"), priceType(true, "RUB"),
+			Price{}, true},
+		{"unknown rate", Calculator{Currency: "GBP", Rates: rates}, offer("10", ""), product("0"), priceType(true, "RUB"),
+			Price{}, true},
+		{"bad tax", Calculator{}, offer("10", ""), product("двадцать"), priceType(true, "RUB"),
+			Price{}, true},
+		{"bad price", Calculator{}, offer("дорого", ""), product("20"), priceType(true, "RUB"),
+			Price{}, true},
+		{"no price", Calculator{}, offer("", ""), product("20"), priceType(true, "RUB"),
+			Price{}, true},
+	} {
+		p, err := c.calc.Price(c.offer, c.product, c.typ)
+		assert.Equal(t, c.err, err != nil, c.name)
+		assert.Equal(t, c.expected, p, c.name)
+	}
+
+	_, err := (&Calculator{}).Price(&xml.Предложение{}, nil, priceType(true, "RUB"))
+	assert.Equal(t, ErrNoPrice, err)
+}
+
+func TestRound(t *testing.T) {
+	for _, c := range []struct {
+		value    float64
+		currency string
+		expected float64
+	}{
+		{2.675, "RUB", 2.68},
+		{1.005, "USD", 1.01},
+		{-2.675, "RUB", -2.68},
+		{2.674, "EUR", 2.67},
+		{2.5, "JPY", 3},
+		{1.2345, "KWD", 1.235},
+		{10, "XXX", 10},
+	} {
+		assert.Equal(t, c.expected, Round(c.value, c.currency), "%v %s", c.value, c.currency)
+	}
+}
+
+func TestTable(t *testing.T) {
+	rates := Table{Base: "RUB", Rates: map[string]float64{"USD": 64, "EUR": 80}}
+	for _, c := range []struct {
+		from, to string
+		rate     float64
+		err      bool
+	}{
+		{"USD", "RUB", 64, false},
+		{"RUB", "EUR", 1.0 / 80, false},
+		{"EUR", "USD", 1.25, false},
+		{"GBP", "GBP", 1, false},
+		{"GBP", "RUB", 0, true},
+		{"RUB", "GBP", 0, true},
+	} {
+		rate, err := rates.Rate(c.from, c.to)
+		assert.Equal(t, c.err, err != nil, c.from+c.to)
+		assert.InDelta(t, c.rate, rate, 1e-12, c.from+c.to)
+	}
+}
+
+func TestTaxRate(t *testing.T) {
+	for s, expected := range map[string]float64{"20": 20, "10%": 10, " 0 ": 0, "Без НДС": 0, "без ндс": 0, "": 0, "18,5": 18.5} {
+		rate, err := TaxRate(s)
+		assert.Nil(t, err, s)
+		assert.Equal(t, expected, rate, s)
+	}
+	for _, s := range []string{"двадцать", "-20"} {
+		_, err := TaxRate(s)
+		assert.NotNil(t, err, s)
+	}
+}
+
+func TestExport(t *testing.T) {
+	product := &xml.ReadMust("../xml/testdata/import.xml").Каталог.Товары[1]
+	pack := xml.ReadMust("../xml/testdata/offers.xml").ПакетПредложений
+	var offer *xml.Предложение
+	for idx := range pack.Предложения {
+		if pack.Предложения[idx].Ид == product.Ид {
+			offer = &pack.Предложения[idx]
+		}
+	}
+
+	p, err := (&Calculator{}).Price(offer, product, &pack.ТипыЦен[0])
+	assert.Nil(t, err)
+	assert.Equal(t, Price{Net: 2875.42, Gross: 3450.5, VAT: 20, Currency: "RUB"}, p)
+}
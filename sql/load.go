@@ -0,0 +1,342 @@
+package sql
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/sevkin/go-cml/xml"
+)
+
+type (
+	// Loader upserts documents into tables created by DDL
+	Loader struct {
+		DB      *sql.DB
+		Dialect Dialect
+		// Batch is the number of rows per statement
+		Batch int
+	}
+
+	// batch collects rows of one table
+	batch struct {
+		*table
+		rows [][]interface{}
+	}
+)
+
+// DefaultBatch is used when Loader.Batch is zero
+const DefaultBatch = 500
+
+// NewLoader returns Loader with default batch size
+func NewLoader(db *sql.DB, dialect Dialect) *Loader {
+	return &Loader{DB: db, Dialect: dialect, Batch: DefaultBatch}
+}
+
+func (b *batch) add(values ...interface{}) {
+	b.rows = append(b.rows, values)
+}
+
+// numeric returns nil for empty CommerceML number so NUMERIC column gets NULL
+func numeric(s string) interface{} {
+	s = strings.Replace(strings.TrimSpace(s), ",", ".", 1)
+	if len(s) == 0 {
+		return nil
+	}
+	return s
+}
+
+// Create executes DDL
+func (l *Loader) Create() error {
+	for _, stmt := range DDL() {
+		if _, err := l.DB.Exec(stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Load stores x in single transaction.
+// Full Каталог marks products of the same catalog missing in it as deleted,
+// full ПакетПредложений replaces offers of the same package.
+// Child rows (groups of product, properties, prices, stock...)
+// of every loaded product and offer are replaced.
+// ИзменениеПакетаПредложений replaces prices and stock of its offers only
+func (l *Loader) Load(x *xml.КоммерческаяИнформация) error {
+	tx, err := l.DB.Begin()
+	if err != nil {
+		return err
+	}
+	if err := l.load(tx, x); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+func (l *Loader) load(tx *sql.Tx, x *xml.КоммерческаяИнформация) error {
+	var batches []*batch
+
+	if x.Классификатор != nil {
+		g := &batch{table: groups}
+		addGroups(g, x.Классификатор.Группы, nil)
+		p := &batch{table: properties}
+		v := &batch{table: propertyValues}
+		for _, prop := range x.Классификатор.Properties() {
+			p.add(prop.Ид, prop.Наименование, prop.ТипЗначений)
+			for _, val := range prop.ВариантыЗначений {
+				v.add(prop.Ид, val.ИдЗначения, val.Значение)
+			}
+		}
+		batches = append(batches, g, p, v)
+	}
+
+	if x.Каталог != nil {
+		if !x.Каталог.СодержитТолькоИзменения {
+			if _, err := tx.Exec(fmt.Sprintf("UPDATE %s SET deleted = %s WHERE catalog_id = %s",
+				products.name, l.Dialect.Placeholder(1), l.Dialect.Placeholder(2)),
+				true, x.Каталог.Ид); err != nil {
+				return err
+			}
+		}
+
+		ids := make([]interface{}, len(x.Каталог.Товары))
+		for idx := range x.Каталог.Товары {
+			ids[idx] = x.Каталог.Товары[idx].Ид
+		}
+		for _, t := range []*table{productGroups, productImages, productProperties, productTaxes, requisites} {
+			if err := l.delete(tx, t, ids); err != nil {
+				return err
+			}
+		}
+
+		p := &batch{table: products}
+		pg := &batch{table: productGroups}
+		pi := &batch{table: productImages}
+		pp := &batch{table: productProperties}
+		pt := &batch{table: productTaxes}
+		r := &batch{table: requisites}
+		for idx := range x.Каталог.Товары {
+			product := &x.Каталог.Товары[idx]
+			var manufacturer interface{}
+			if product.Изготовитель != nil {
+				manufacturer = product.Изготовитель.Наименование
+			}
+			p.add(product.Ид, x.Каталог.Ид, product.Артикул, product.Наименование, product.Описание,
+				product.Штрихкод, product.БазоваяЕдиница.Name(), product.Страна,
+				manufacturer, product.Deleted())
+			for _, id := range product.Группы {
+				pg.add(product.Ид, id)
+			}
+			for pos, path := range product.Картинка {
+				pi.add(product.Ид, pos, path)
+			}
+			if product.ЗначенияСвойств != nil {
+				for _, v := range *product.ЗначенияСвойств {
+					pp.add(product.Ид, v.Ид, v.Значение)
+				}
+			}
+			if product.СтавкиНалогов != nil {
+				for _, v := range *product.СтавкиНалогов {
+					pt.add(product.Ид, v.Наименование, v.Ставка)
+				}
+			}
+			for _, v := range product.ЗначенияРеквизитов {
+				r.add(product.Ид, v.Наименование, v.Значение)
+			}
+		}
+		batches = append(batches, p, pg, pi, pp, pt, r)
+	}
+
+	if pack := x.ПакетПредложений; pack != nil {
+		ids := make([]interface{}, len(pack.Предложения))
+		for idx := range pack.Предложения {
+			ids[idx] = pack.Предложения[idx].Ид
+		}
+		if pack.СодержитТолькоИзменения {
+			for _, t := range []*table{prices, stock, offers} {
+				if err := l.delete(tx, t, ids); err != nil {
+					return err
+				}
+			}
+		} else if err := l.deletePackage(tx, pack.Ид); err != nil {
+			return err
+		}
+
+		pt := &batch{table: priceTypes}
+		for _, t := range pack.ТипыЦен {
+			pt.add(t.Ид, t.Наименование, t.Валюта, t.Налог.Наименование, t.Налог.УчтеноВСумме)
+		}
+		w := &batch{table: warehouses}
+		for _, s := range pack.Склады {
+			w.add(s.Ид, s.Наименование)
+		}
+		o := &batch{table: offers}
+		p := &batch{table: prices}
+		s := &batch{table: stock}
+		for idx := range pack.Предложения {
+			offer := &pack.Предложения[idx]
+			o.add(offer.Ид, pack.Ид, offer.ProductID(), offer.Артикул, offer.Наименование,
+				numeric(offer.Количество))
+			for _, price := range offer.Цены {
+				p.add(offer.Ид, price.ИдТипаЦены, numeric(price.ЦенаЗаЕдиницу),
+					price.Валюта, price.Единица, numeric(price.Коэффициент))
+			}
+			for _, rest := range offer.Склад {
+				s.add(offer.Ид, rest.ИдСклада, numeric(rest.КоличествоНаСкладе))
+			}
+		}
+		batches = append(batches, pt, w, o, p, s)
+	}
+
+	if change := x.ИзменениеПакетаПредложений; change != nil {
+		var priced, stocked []interface{}
+		q := &batch{table: offerQuantity}
+		p := &batch{table: prices}
+		s := &batch{table: stock}
+		for idx := range change.Предложения {
+			c := &change.Предложения[idx]
+			offer := c.Offer()
+			if len(c.Prices()) > 0 {
+				priced = append(priced, c.Ид)
+			}
+			if len(c.Rests()) > 0 {
+				stocked = append(stocked, c.Ид)
+				q.add(c.Ид, numeric(offer.Количество))
+			}
+			for _, price := range offer.Цены {
+				p.add(offer.Ид, price.ИдТипаЦены, numeric(price.ЦенаЗаЕдиницу),
+					price.Валюта, price.Единица, numeric(price.Коэффициент))
+			}
+			for _, rest := range offer.Склад {
+				s.add(offer.Ид, rest.ИдСклада, numeric(rest.КоличествоНаСкладе))
+			}
+		}
+		if err := l.delete(tx, prices, priced); err != nil {
+			return err
+		}
+		if err := l.delete(tx, stock, stocked); err != nil {
+			return err
+		}
+		batches = append(batches, q, p, s)
+	}
+
+	for _, b := range batches {
+		if err := l.upsert(tx, b); err != nil {
+			return fmt.Errorf("%s: %v", b.name, err)
+		}
+	}
+	return nil
+}
+
+func addGroups(b *batch, groups []xml.Группа, parent interface{}) {
+	for _, g := range groups {
+		b.add(g.Ид, parent, g.Наименование)
+		if g.Группы != nil {
+			addGroups(b, *g.Группы, g.Ид)
+		}
+	}
+}
+
+// rows returns max rows per statement of n columns
+func (l *Loader) rows(n int) int {
+	size := l.Batch
+	if size <= 0 {
+		size = DefaultBatch
+	}
+	if l.Dialect.MaxParams > 0 && size*n > l.Dialect.MaxParams {
+		size = l.Dialect.MaxParams / n
+	}
+	return size
+}
+
+// upsert inserts rows updating non key columns on primary key conflict
+func (l *Loader) upsert(tx *sql.Tx, b *batch) error {
+	n := len(b.columns)
+	head := fmt.Sprintf("INSERT INTO %s (%s) VALUES ", b.name, strings.Join(b.names(0, n), ", "))
+	tail := fmt.Sprintf(" ON CONFLICT (%s) DO NOTHING", strings.Join(b.names(0, b.key), ", "))
+	if b.key < n {
+		set := b.names(b.key, n)
+		for idx, c := range set {
+			set[idx] = fmt.Sprintf("%s = excluded.%s", c, c)
+		}
+		tail = fmt.Sprintf(" ON CONFLICT (%s) DO UPDATE SET %s",
+			strings.Join(b.names(0, b.key), ", "), strings.Join(set, ", "))
+	}
+
+	size := l.rows(n)
+	for from := 0; from < len(b.rows); from += size {
+		to := from + size
+		if to > len(b.rows) {
+			to = len(b.rows)
+		}
+		// duplicate keys in one statement are not allowed by Postgres
+		rows := unique(b.rows[from:to], b.key)
+
+		values := make([]string, len(rows))
+		args := make([]interface{}, 0, len(rows)*n)
+		for idx, row := range rows {
+			params := make([]string, n)
+			for c := range params {
+				params[c] = l.Dialect.Placeholder(len(args) + c + 1)
+			}
+			values[idx] = "(" + strings.Join(params, ", ") + ")"
+			args = append(args, row...)
+		}
+
+		if _, err := tx.Exec(head+strings.Join(values, ", ")+tail, args...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// unique returns rows with the last one of duplicated keys
+func unique(rows [][]interface{}, key int) [][]interface{} {
+	last := make(map[string]int, len(rows))
+	for idx, row := range rows {
+		last[fmt.Sprintf("%#v", row[:key])] = idx
+	}
+	if len(last) == len(rows) {
+		return rows
+	}
+	result := make([][]interface{}, 0, len(last))
+	for idx, row := range rows {
+		if last[fmt.Sprintf("%#v", row[:key])] == idx {
+			result = append(result, row)
+		}
+	}
+	return result
+}
+
+// deletePackage removes offers of package with their prices and stock
+func (l *Loader) deletePackage(tx *sql.Tx, id string) error {
+	for _, t := range []*table{prices, stock} {
+		if _, err := tx.Exec(fmt.Sprintf("DELETE FROM %s WHERE %s IN (SELECT id FROM %s WHERE package_id = %s)",
+			t.name, t.columns[0].name, offers.name, l.Dialect.Placeholder(1)), id); err != nil {
+			return err
+		}
+	}
+	_, err := tx.Exec(fmt.Sprintf("DELETE FROM %s WHERE package_id = %s",
+		offers.name, l.Dialect.Placeholder(1)), id)
+	return err
+}
+
+// delete removes rows of t with first key column in ids
+func (l *Loader) delete(tx *sql.Tx, t *table, ids []interface{}) error {
+	size := l.rows(1)
+	for from := 0; from < len(ids); from += size {
+		to := from + size
+		if to > len(ids) {
+			to = len(ids)
+		}
+		params := make([]string, to-from)
+		for idx := range params {
+			params[idx] = l.Dialect.Placeholder(idx + 1)
+		}
+		if _, err := tx.Exec(fmt.Sprintf("DELETE FROM %s WHERE %s IN (%s)",
+			t.name, t.columns[0].name, strings.Join(params, ", ")), ids[from:to]...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
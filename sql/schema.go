@@ -0,0 +1,118 @@
+// Package sql stores CommerceML documents in relational database
+// via database/sql: DDL of tables and loader with batched upserts
+package sql
+
+import (
+	"fmt"
+	"strings"
+)
+
+type (
+	// Dialect of SQL. Both dialects support INSERT ... ON CONFLICT upserts
+	Dialect struct {
+		// Placeholder returns n-th (from 1) query parameter
+		Placeholder func(n int) string
+		// MaxParams is the limit of parameters per statement
+		MaxParams int
+	}
+
+	column struct {
+		name string
+		typ  string
+	}
+
+	// table with primary key of first key columns
+	table struct {
+		name    string
+		columns []column
+		key     int
+	}
+)
+
+var (
+	// Postgres dialect
+	Postgres = Dialect{
+		Placeholder: func(n int) string { return fmt.Sprintf("$%d", n) },
+		MaxParams:   65535,
+	}
+
+	// SQLite dialect
+	SQLite = Dialect{
+		Placeholder: func(int) string { return "?" },
+		MaxParams:   999,
+	}
+)
+
+var (
+	groups = &table{"cml_groups", []column{
+		{"id", "TEXT"}, {"parent_id", "TEXT"}, {"name", "TEXT"}}, 1}
+	properties = &table{"cml_properties", []column{
+		{"id", "TEXT"}, {"name", "TEXT"}, {"type", "TEXT"}}, 1}
+	propertyValues = &table{"cml_property_values", []column{
+		{"property_id", "TEXT"}, {"value_id", "TEXT"}, {"value", "TEXT"}}, 2}
+	products = &table{"cml_products", []column{
+		{"id", "TEXT"}, {"catalog_id", "TEXT"}, {"article", "TEXT"}, {"name", "TEXT"}, {"description", "TEXT"},
+		{"barcode", "TEXT"}, {"unit", "TEXT"}, {"country", "TEXT"}, {"manufacturer", "TEXT"},
+		{"deleted", "BOOLEAN"}}, 1}
+	productGroups = &table{"cml_product_groups", []column{
+		{"product_id", "TEXT"}, {"group_id", "TEXT"}}, 2}
+	productImages = &table{"cml_product_images", []column{
+		{"product_id", "TEXT"}, {"position", "INTEGER"}, {"path", "TEXT"}}, 2}
+	productProperties = &table{"cml_product_properties", []column{
+		{"product_id", "TEXT"}, {"property_id", "TEXT"}, {"value", "TEXT"}}, 3}
+	productTaxes = &table{"cml_product_taxes", []column{
+		{"product_id", "TEXT"}, {"name", "TEXT"}, {"rate", "TEXT"}}, 2}
+	requisites = &table{"cml_requisites", []column{
+		{"product_id", "TEXT"}, {"name", "TEXT"}, {"value", "TEXT"}}, 2}
+	priceTypes = &table{"cml_price_types", []column{
+		{"id", "TEXT"}, {"name", "TEXT"}, {"currency", "TEXT"},
+		{"tax_name", "TEXT"}, {"tax_included", "BOOLEAN"}}, 1}
+	warehouses = &table{"cml_warehouses", []column{
+		{"id", "TEXT"}, {"name", "TEXT"}}, 1}
+	offers = &table{"cml_offers", []column{
+		{"id", "TEXT"}, {"package_id", "TEXT"}, {"product_id", "TEXT"}, {"article", "TEXT"}, {"name", "TEXT"},
+		{"quantity", "NUMERIC"}}, 1}
+	prices = &table{"cml_prices", []column{
+		{"offer_id", "TEXT"}, {"price_type_id", "TEXT"}, {"price", "NUMERIC"},
+		{"currency", "TEXT"}, {"unit", "TEXT"}, {"ratio", "NUMERIC"}}, 2}
+	stock = &table{"cml_stock", []column{
+		{"offer_id", "TEXT"}, {"warehouse_id", "TEXT"}, {"quantity", "NUMERIC"}}, 2}
+
+	tables = []*table{groups, properties, propertyValues,
+		products, productGroups, productImages, productProperties, productTaxes, requisites,
+		priceTypes, warehouses, offers, prices, stock}
+)
+
+// offerQuantity updates quantity of offers only
+var offerQuantity = &table{offers.name, []column{offers.columns[0], offers.columns[5]}, 1}
+
+func (t *table) names(from, to int) []string {
+	names := make([]string, to-from)
+	for idx, c := range t.columns[from:to] {
+		names[idx] = c.name
+	}
+	return names
+}
+
+func (t *table) create() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "CREATE TABLE IF NOT EXISTS %s (\n", t.name)
+	for idx, c := range t.columns {
+		fmt.Fprintf(&b, "  %s %s", c.name, c.typ)
+		if idx < t.key {
+			b.WriteString(" NOT NULL")
+		}
+		b.WriteString(",\n")
+	}
+	fmt.Fprintf(&b, "  PRIMARY KEY (%s)\n)", strings.Join(t.names(0, t.key), ", "))
+	return b.String()
+}
+
+// DDL returns statements creating tables if not exist
+func DDL() []string {
+	ddl := make([]string, len(tables))
+	for idx, t := range tables {
+		ddl[idx] = t.create()
+	}
+	return ddl
+}
@@ -0,0 +1,347 @@
+package sql
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sevkin/go-cml/xml"
+)
+
+// fake is in-memory driver which understands statements of Loader only
+type (
+	fake struct {
+		sync.Mutex
+		dbs map[string]*fakeDB
+	}
+
+	fakeDB struct {
+		sync.Mutex
+		tables map[string]map[string]map[string]driver.Value
+		execs  []string
+	}
+
+	fakeConn struct{ db *fakeDB }
+	fakeTx   struct{}
+	fakeStmt struct {
+		db    *fakeDB
+		query string
+	}
+)
+
+var (
+	fakeDriver = &fake{dbs: make(map[string]*fakeDB)}
+
+	createRe = regexp.MustCompile(`^CREATE TABLE IF NOT EXISTS (\w+)`)
+	insertRe = regexp.MustCompile(`^INSERT INTO (\w+) \(([^)]+)\) VALUES .* ON CONFLICT \(([^)]+)\) DO`)
+	deleteRe = regexp.MustCompile(`^DELETE FROM (\w+)(?: WHERE (\w+) IN)?`)
+	scopeRe  = regexp.MustCompile(`^DELETE FROM (\w+) WHERE (\w+) (?:IN \(SELECT (\w+) FROM (\w+) WHERE (\w+) =|=)`)
+	updateRe = regexp.MustCompile(`^UPDATE (\w+) SET (\w+) = \S+(?: WHERE (\w+) =)?`)
+)
+
+func init() {
+	sql.Register("cmlfake", fakeDriver)
+}
+
+func (f *fake) Open(name string) (driver.Conn, error) {
+	f.Lock()
+	defer f.Unlock()
+	db, found := f.dbs[name]
+	if !found {
+		db = &fakeDB{tables: make(map[string]map[string]map[string]driver.Value)}
+		f.dbs[name] = db
+	}
+	return &fakeConn{db}, nil
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeStmt{c.db, query}, nil
+}
+func (c *fakeConn) Close() error              { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error) { return fakeTx{}, nil }
+func (fakeTx) Commit() error                  { return nil }
+func (fakeTx) Rollback() error                { return nil }
+func (s *fakeStmt) Close() error              { return nil }
+func (s *fakeStmt) NumInput() int             { return -1 }
+func (s *fakeStmt) Query([]driver.Value) (driver.Rows, error) {
+	return nil, fmt.Errorf("query is not supported")
+}
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	db := s.db
+	db.Lock()
+	defer db.Unlock()
+	db.execs = append(db.execs, s.query)
+
+	if m := createRe.FindStringSubmatch(s.query); m != nil {
+		if db.tables[m[1]] == nil {
+			db.tables[m[1]] = make(map[string]map[string]driver.Value)
+		}
+		return driver.RowsAffected(0), nil
+	}
+	if m := insertRe.FindStringSubmatch(s.query); m != nil {
+		t := db.tables[m[1]]
+		if t == nil {
+			return nil, fmt.Errorf("no table %s", m[1])
+		}
+		columns := strings.Split(m[2], ", ")
+		keys := strings.Split(m[3], ", ")
+		if len(args)%len(columns) != 0 {
+			return nil, fmt.Errorf("%d args for %d columns", len(args), len(columns))
+		}
+		for from := 0; from < len(args); from += len(columns) {
+			row := make(map[string]driver.Value)
+			for idx, c := range columns {
+				row[c] = args[from+idx]
+			}
+			key := make([]string, len(keys))
+			for idx, k := range keys {
+				key[idx] = fmt.Sprint(row[k])
+			}
+			// conflicting row keeps columns not inserted
+			if old, found := t[strings.Join(key, "|")]; found {
+				for c, v := range row {
+					old[c] = v
+				}
+				continue
+			}
+			t[strings.Join(key, "|")] = row
+		}
+		return driver.RowsAffected(len(args) / len(columns)), nil
+	}
+	if m := scopeRe.FindStringSubmatch(s.query); m != nil {
+		// WHERE column = arg or WHERE column IN (SELECT ... WHERE column = arg)
+		values := map[driver.Value]bool{args[0]: true}
+		if len(m[3]) > 0 {
+			values = make(map[driver.Value]bool)
+			for _, row := range db.tables[m[4]] {
+				if row[m[5]] == args[0] {
+					values[row[m[3]]] = true
+				}
+			}
+		}
+		t := db.tables[m[1]]
+		for key, row := range t {
+			if values[row[m[2]]] {
+				delete(t, key)
+			}
+		}
+		return driver.RowsAffected(0), nil
+	}
+	if m := deleteRe.FindStringSubmatch(s.query); m != nil {
+		t := db.tables[m[1]]
+		for key, row := range t {
+			if len(m[2]) == 0 {
+				delete(t, key)
+				continue
+			}
+			for _, arg := range args {
+				if row[m[2]] == arg {
+					delete(t, key)
+				}
+			}
+		}
+		return driver.RowsAffected(0), nil
+	}
+	if m := updateRe.FindStringSubmatch(s.query); m != nil {
+		for _, row := range db.tables[m[1]] {
+			if len(m[3]) == 0 || row[m[3]] == args[1] {
+				row[m[2]] = args[0]
+			}
+		}
+		return driver.RowsAffected(0), nil
+	}
+	return nil, fmt.Errorf("unexpected statement %s", s.query)
+}
+
+func open(t *testing.T) (*Loader, *fakeDB) {
+	db, err := sql.Open("cmlfake", t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	l := NewLoader(db, Postgres)
+	if err := l.Create(); err != nil {
+		t.Fatal(err)
+	}
+	return l, fakeDriver.dbs[t.Name()]
+}
+
+func TestDDL(t *testing.T) {
+	ddl := DDL()
+	assert.Equal(t, len(tables), len(ddl))
+	assert.Equal(t, "CREATE TABLE IF NOT EXISTS cml_prices (\n"+
+		"  offer_id TEXT NOT NULL,\n"+
+		"  price_type_id TEXT NOT NULL,\n"+
+		"  price NUMERIC,\n"+
+		"  currency TEXT,\n"+
+		"  unit TEXT,\n"+
+		"  ratio NUMERIC,\n"+
+		"  PRIMARY KEY (offer_id, price_type_id)\n)", prices.create())
+}
+
+func TestLoad(t *testing.T) {
+	l, db := open(t)
+	assert.Nil(t, l.Load(xml.ReadMust("../xml/testdata/import.xml")))
+	assert.Nil(t, l.Load(xml.ReadMust("../xml/testdata/offers.xml")))
+
+	assert.Equal(t, 4, len(db.tables["cml_groups"]))
+	assert.Equal(t, "9e1f0a10-2b3c-11e9-80d4-0cc47a7c2f11",
+		db.tables["cml_groups"]["9e1f0a11-2b3c-11e9-80d4-0cc47a7c2f11"]["parent_id"])
+	assert.Equal(t, 4, len(db.tables["cml_products"]))
+	assert.Equal(t, 2, len(db.tables["cml_price_types"]))
+	assert.Equal(t, 2, len(db.tables["cml_warehouses"]))
+	assert.Equal(t, 4, len(db.tables["cml_offers"]))
+
+	offer := db.tables["cml_offers"]["c5e4a1b1-3d2e-11e9-80d4-0cc47a7c2f11"]
+	assert.Equal(t, "12", offer["quantity"])
+	price := db.tables["cml_prices"]["c5e4a1b1-3d2e-11e9-80d4-0cc47a7c2f11|d1c0a2b3-4e5f-11e9-80d4-0cc47a7c2f11"]
+	assert.Equal(t, "3450.50", price["price"])
+
+	deleted := 0
+	for _, p := range db.tables["cml_products"] {
+		if p["deleted"] == true {
+			deleted++
+		}
+	}
+	assert.Equal(t, 1, deleted)
+}
+
+func TestLoadDelta(t *testing.T) {
+	l, db := open(t)
+	assert.Nil(t, l.Load(xml.ReadMust("../xml/testdata/import.xml")))
+	assert.Nil(t, l.Load(xml.ReadMust("../xml/testdata/offers.xml")))
+
+	delta := &xml.КоммерческаяИнформация{
+		Каталог: &xml.Каталог{СодержитТолькоИзменения: true, Товары: []xml.Товар{
+			{Ид: "new", Наименование: "Новый", Группы: []string{"9e1f0a13-2b3c-11e9-80d4-0cc47a7c2f11"}},
+		}},
+		ПакетПредложений: &xml.ПакетПредложений{СодержитТолькоИзменения: true, Предложения: []xml.Предложение{
+			{Ид: "c5e4a1b1-3d2e-11e9-80d4-0cc47a7c2f11", Количество: "0",
+				Цены: []xml.Цена{{ИдТипаЦены: "d1c0a2b3-4e5f-11e9-80d4-0cc47a7c2f11", ЦенаЗаЕдиницу: "3000,00"}}},
+		}},
+	}
+	assert.Nil(t, l.Load(delta))
+
+	assert.Equal(t, 5, len(db.tables["cml_products"]))
+	for id, p := range db.tables["cml_products"] {
+		if id != "new" && p["deleted"] == true {
+			assert.Equal(t, "Платье зимнее (снято с продажи)", p["name"])
+		}
+	}
+	assert.Equal(t, 4, len(db.tables["cml_offers"]))
+	assert.Equal(t, "0", db.tables["cml_offers"]["c5e4a1b1-3d2e-11e9-80d4-0cc47a7c2f11"]["quantity"])
+	assert.Equal(t, "3000.00",
+		db.tables["cml_prices"]["c5e4a1b1-3d2e-11e9-80d4-0cc47a7c2f11|d1c0a2b3-4e5f-11e9-80d4-0cc47a7c2f11"]["price"])
+	for _, s := range db.tables["cml_stock"] {
+		assert.NotEqual(t, "c5e4a1b1-3d2e-11e9-80d4-0cc47a7c2f11", s["offer_id"])
+	}
+
+	// full catalog marks missing products of the same catalog deleted
+	full := &xml.КоммерческаяИнформация{Каталог: &xml.Каталог{Ид: "other", Товары: []xml.Товар{{Ид: "other"}}}}
+	assert.Nil(t, l.Load(full))
+	for id, p := range db.tables["cml_products"] {
+		if id != "other" {
+			assert.Equal(t, id == "c5e4a1b3-3d2e-11e9-80d4-0cc47a7c2f11", p["deleted"], id)
+		}
+	}
+	full.Каталог = &xml.Каталог{Ид: "3d6c8e2a-9b1f-11e9-80d4-0cc47a7c2f11", Товары: []xml.Товар{{Ид: "new"}}}
+	assert.Nil(t, l.Load(full))
+	for id, p := range db.tables["cml_products"] {
+		assert.Equal(t, id != "new" && id != "other", p["deleted"], id)
+	}
+	for _, g := range db.tables["cml_product_groups"] {
+		assert.NotEqual(t, "new", g["product_id"])
+	}
+}
+
+func TestLoadPackages(t *testing.T) {
+	l, db := open(t)
+	assert.Nil(t, l.Load(xml.ReadMust("../xml/testdata/offers.xml")))
+	other := &xml.КоммерческаяИнформация{ПакетПредложений: &xml.ПакетПредложений{Ид: "other",
+		Предложения: []xml.Предложение{{Ид: "o1", Количество: "1",
+			Цены:  []xml.Цена{{ИдТипаЦены: "retail", ЦенаЗаЕдиницу: "10"}},
+			Склад: []xml.Остаток{{ИдСклада: "w1", КоличествоНаСкладе: "1"}}}},
+	}}
+	assert.Nil(t, l.Load(other))
+	assert.Equal(t, 5, len(db.tables["cml_offers"]))
+
+	// full package replaces offers of its own only
+	assert.Nil(t, l.Load(xml.ReadMust("../xml/testdata/offers.xml")))
+	assert.Equal(t, 5, len(db.tables["cml_offers"]))
+	assert.Equal(t, "other", db.tables["cml_offers"]["o1"]["package_id"])
+	assert.Equal(t, "10", db.tables["cml_prices"]["o1|retail"]["price"])
+	assert.Equal(t, "1", db.tables["cml_stock"]["o1|w1"]["quantity"])
+
+	other.ПакетПредложений.Предложения = nil
+	assert.Nil(t, l.Load(other))
+	assert.Equal(t, 4, len(db.tables["cml_offers"]))
+	assert.Nil(t, db.tables["cml_prices"]["o1|retail"])
+	assert.Nil(t, db.tables["cml_stock"]["o1|w1"])
+	assert.NotNil(t, db.tables["cml_prices"]["c5e4a1b1-3d2e-11e9-80d4-0cc47a7c2f11|d1c0a2b3-4e5f-11e9-80d4-0cc47a7c2f11"])
+}
+
+func TestLoadChanges(t *testing.T) {
+	l, db := open(t)
+	assert.Nil(t, l.Load(xml.ReadMust("../xml/testdata/import.xml")))
+	assert.Nil(t, l.Load(xml.ReadMust("../xml/testdata/offers.xml")))
+	assert.Nil(t, l.Load(xml.ReadMust("../xml/testdata/prices.xml")))
+	assert.Nil(t, l.Load(xml.ReadMust("../xml/testdata/rests.xml")))
+
+	id := "c5e4a1b1-3d2e-11e9-80d4-0cc47a7c2f11"
+	offer := db.tables["cml_offers"][id]
+	assert.Equal(t, "7", offer["quantity"])
+	assert.Equal(t, "БР-014", offer["article"])
+	assert.Equal(t, "3300", db.tables["cml_prices"][id+"|d1c0a2b3-4e5f-11e9-80d4-0cc47a7c2f11"]["price"])
+	assert.Equal(t, "2800", db.tables["cml_prices"][id+"|d1c0a2b4-4e5f-11e9-80d4-0cc47a7c2f11"]["price"])
+	assert.Equal(t, "4", db.tables["cml_stock"][id+"|e2f1b3c4-5d6e-11e9-80d4-0cc47a7c2f11"]["quantity"])
+	assert.Equal(t, "3", db.tables["cml_stock"][id+"|e2f1b3c5-5d6e-11e9-80d4-0cc47a7c2f11"]["quantity"])
+
+	// total only drops stock on warehouses
+	id = "c5e4a1b2-3d2e-11e9-80d4-0cc47a7c2f11"
+	assert.Equal(t, "0", db.tables["cml_offers"][id]["quantity"])
+	for _, s := range db.tables["cml_stock"] {
+		assert.NotEqual(t, id, s["offer_id"])
+	}
+	assert.Equal(t, 4, len(db.tables["cml_offers"]))
+}
+
+func TestBatch(t *testing.T) {
+	l, db := open(t)
+	l.Batch = 2
+	start := len(db.execs)
+
+	x := &xml.КоммерческаяИнформация{ПакетПредложений: &xml.ПакетПредложений{
+		СодержитТолькоИзменения: true,
+		Предложения: []xml.Предложение{
+			{Ид: "1", Количество: "1"}, {Ид: "2"}, {Ид: "3"}, {Ид: "1", Количество: "5"}, {Ид: "4"},
+		},
+	}}
+	assert.Nil(t, l.Load(x))
+
+	inserts := 0
+	for _, q := range db.execs[start:] {
+		if strings.HasPrefix(q, "INSERT INTO cml_offers ") {
+			inserts++
+		}
+	}
+	assert.Equal(t, 3, inserts)
+	assert.Equal(t, 4, len(db.tables["cml_offers"]))
+	assert.Equal(t, "5", db.tables["cml_offers"]["1"]["quantity"])
+	assert.Nil(t, db.tables["cml_offers"]["2"]["quantity"])
+
+	l.Dialect = SQLite
+	l.Batch = 10000
+	assert.Equal(t, 999/5, l.rows(5))
+}
+
+func TestUnique(t *testing.T) {
+	rows := [][]interface{}{{"a", "b", 1}, {"ab", "", 2}, {"a", "b", 3}}
+	assert.Equal(t, [][]interface{}{{"ab", "", 2}, {"a", "b", 3}}, unique(rows, 2))
+	assert.Equal(t, rows[:2], unique(rows[:2], 2))
+}
@@ -0,0 +1,146 @@
+package xml
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Canonical returns copy of x with stable form for diffs and hashing:
+// groups, products, offers, price types, warehouses, prices and stock
+// (of ПакетПредложений and ИзменениеПакетаПредложений) sorted by Ид,
+// whitespace of all values trimmed, that of identifiers and names
+// also collapsed to single spaces.
+// Booleans are always written as true/false
+func Canonical(x *КоммерческаяИнформация) (*КоммерческаяИнформация, error) {
+	// deep copy through marshaling, x is not modified
+	buf, err := xml.Marshal(x)
+	if err != nil {
+		return nil, err
+	}
+	c := new(КоммерческаяИнформация)
+	if err := xml.Unmarshal(buf, c); err != nil {
+		return nil, err
+	}
+
+	normalize(reflect.ValueOf(c).Elem(), false)
+
+	if c.Классификатор != nil {
+		sortGroups(c.Классификатор.Группы)
+	}
+	if c.Каталог != nil {
+		products := c.Каталог.Товары
+		sort.SliceStable(products, func(i, j int) bool { return products[i].Ид < products[j].Ид })
+		for idx := range products {
+			sort.Strings(products[idx].Группы)
+		}
+	}
+	if pack := c.ПакетПредложений; pack != nil {
+		sort.SliceStable(pack.ТипыЦен, func(i, j int) bool { return pack.ТипыЦен[i].Ид < pack.ТипыЦен[j].Ид })
+		sort.SliceStable(pack.Склады, func(i, j int) bool { return pack.Склады[i].Ид < pack.Склады[j].Ид })
+		offers := pack.Предложения
+		sort.SliceStable(offers, func(i, j int) bool { return offers[i].Ид < offers[j].Ид })
+		for idx := range offers {
+			prices, stock := offers[idx].Цены, offers[idx].Склад
+			sort.SliceStable(prices, func(i, j int) bool { return prices[i].ИдТипаЦены < prices[j].ИдТипаЦены })
+			sort.SliceStable(stock, func(i, j int) bool { return stock[i].ИдСклада < stock[j].ИдСклада })
+			for s := range stock {
+				stock[s].Склад = ""
+			}
+		}
+	}
+	if change := c.ИзменениеПакетаПредложений; change != nil {
+		offers := change.Предложения
+		sort.SliceStable(offers, func(i, j int) bool { return offers[i].Ид < offers[j].Ид })
+		for idx := range offers {
+			prices, rests := offers[idx].Prices(), offers[idx].Rests()
+			sort.SliceStable(prices, func(i, j int) bool { return prices[i].ИдТипаЦены < prices[j].ИдТипаЦены })
+			// total goes first, then warehouses by Ид
+			sort.SliceStable(rests, func(i, j int) bool { return restKey(rests[i]) < restKey(rests[j]) })
+		}
+	}
+	return c, nil
+}
+
+// WriteCanonical writes canonical form of x to w
+func WriteCanonical(x *КоммерческаяИнформация, w io.Writer) error {
+	c, err := Canonical(x)
+	if err != nil {
+		return err
+	}
+	return Write(c, w)
+}
+
+// Hash returns hex sha256 of canonical form of x. ДатаФормирования is not
+// taken into account, so documents regenerated from the same data have the same hash
+func Hash(x *КоммерческаяИнформация) (string, error) {
+	c, err := Canonical(x)
+	if err != nil {
+		return "", err
+	}
+	c.ДатаФормирования = ""
+
+	var buf bytes.Buffer
+	if err := Write(c, &buf); err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(buf.Bytes())
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func restKey(r ОстатокТовара) string {
+	if r.Склад == nil {
+		return ""
+	}
+	return "\x00" + r.Склад.Ид
+}
+
+func sortGroups(groups []Группа) {
+	sort.SliceStable(groups, func(i, j int) bool { return groups[i].Ид < groups[j].Ид })
+	for _, g := range groups {
+		if g.Группы != nil {
+			sortGroups(*g.Группы)
+		}
+	}
+}
+
+// normalize trims whitespace of all strings reachable from v,
+// identifiers and names are also collapsed to single spaces
+func normalize(v reflect.Value, collapse bool) {
+	switch v.Kind() {
+	case reflect.String:
+		if collapse {
+			v.SetString(strings.Join(strings.Fields(v.String()), " "))
+		} else {
+			v.SetString(strings.TrimSpace(v.String()))
+		}
+	case reflect.Ptr:
+		if !v.IsNil() {
+			normalize(v.Elem(), collapse)
+		}
+	case reflect.Struct:
+		t := v.Type()
+		for idx := 0; idx < v.NumField(); idx++ {
+			normalize(v.Field(idx), collapsed(t.Field(idx).Name))
+		}
+	case reflect.Slice:
+		for idx := 0; idx < v.Len(); idx++ {
+			normalize(v.Index(idx), collapse)
+		}
+	}
+}
+
+// collapsed tells if whitespace of field is collapsed:
+// Ид and references, Наименование, Артикул and Штрихкод
+func collapsed(field string) bool {
+	switch field {
+	case "Наименование", "Артикул", "Штрихкод", "Группы":
+		return true
+	}
+	return strings.HasPrefix(field, "Ид")
+}
@@ -0,0 +1,100 @@
+package xml
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCanonical(t *testing.T) {
+	x := snapshot()
+	x.Классификатор.Группы[0], x.Классификатор.Группы[1] = x.Классификатор.Группы[1], x.Классификатор.Группы[0]
+	x.Каталог.Товары[0].Наименование = "  Брюки \n\t мужские "
+	x.Каталог.Товары[0].Группы = []string{"g2", "g11"}
+	x.Каталог.Товары[0].Описание = "\n  Лён.\n\n  Свободный крой. "
+	x.Каталог.Товары[0], x.Каталог.Товары[2] = x.Каталог.Товары[2], x.Каталог.Товары[0]
+	x.ПакетПредложений.Предложения[0].Цены = append(x.ПакетПредложений.Предложения[0].Цены,
+		Цена{ИдТипаЦены: "opt", ЦенаЗаЕдиницу: "90"})
+	x.ПакетПредложений.Предложения[0].Склад = []Остаток{
+		{ИдСклада: "w2", КоличествоНаСкладе: "1", Склад: "\n  "},
+		{ИдСклада: "w1", КоличествоНаСкладе: "2"},
+	}
+
+	c, err := Canonical(x)
+	assert.Nil(t, err)
+
+	assert.Equal(t, "g1", c.Классификатор.Группы[0].Ид)
+	assert.Equal(t, "p1", c.Каталог.Товары[0].Ид)
+	assert.Equal(t, "Брюки мужские", c.Каталог.Товары[0].Наименование)
+	assert.Equal(t, []string{"g11", "g2"}, c.Каталог.Товары[0].Группы)
+	// free text is only trimmed
+	assert.Equal(t, "Лён.\n\n  Свободный крой.", c.Каталог.Товары[0].Описание)
+	o := c.ПакетПредложений.Предложения[0]
+	assert.Equal(t, "opt", o.Цены[0].ИдТипаЦены)
+	assert.Equal(t, "w1", o.Склад[0].ИдСклада)
+	assert.Equal(t, "", o.Склад[1].Склад)
+
+	// source is not modified
+	assert.Equal(t, "g2", x.Классификатор.Группы[0].Ид)
+	assert.Equal(t, "  Брюки \n\t мужские ", x.Каталог.Товары[2].Наименование)
+}
+
+func TestCanonicalChanges(t *testing.T) {
+	x := ReadMust("testdata/rests.xml")
+	change := x.ИзменениеПакетаПредложений
+	o := &change.Предложения[0]
+	rests := o.Rests()
+	rests[0], rests[2] = rests[2], rests[0]
+	o.Цены = &[]Цена{{ИдТипаЦены: "retail"}, {ИдТипаЦены: "opt"}}
+	change.Предложения[0], change.Предложения[1] = change.Предложения[1], change.Предложения[0]
+
+	c, err := Canonical(x)
+	assert.Nil(t, err)
+	offers := c.ИзменениеПакетаПредложений.Предложения
+	assert.Equal(t, "c5e4a1b1-3d2e-11e9-80d4-0cc47a7c2f11", offers[0].Ид)
+	assert.Equal(t, "opt", offers[0].Prices()[0].ИдТипаЦены)
+	assert.Nil(t, offers[0].Rests()[0].Склад)
+	assert.Equal(t, "e2f1b3c4-5d6e-11e9-80d4-0cc47a7c2f11", offers[0].Rests()[1].Склад.Ид)
+	assert.Equal(t, "e2f1b3c5-5d6e-11e9-80d4-0cc47a7c2f11", offers[0].Rests()[2].Склад.Ид)
+
+	hx, err := Hash(x)
+	assert.Nil(t, err)
+	hy, err := Hash(ReadMust("testdata/rests.xml"))
+	assert.Nil(t, err)
+	assert.NotEqual(t, hx, hy)
+	x.ИзменениеПакетаПредложений.Предложения[1].Цены = nil
+	hx, err = Hash(x)
+	assert.Nil(t, err)
+	assert.Equal(t, hx, hy)
+}
+
+func TestWriteCanonical(t *testing.T) {
+	x, y := snapshot(), snapshot()
+	y.Каталог.Товары[0], y.Каталог.Товары[1] = y.Каталог.Товары[1], y.Каталог.Товары[0]
+	y.Классификатор.Группы[0], y.Классификатор.Группы[1] = y.Классификатор.Группы[1], y.Классификатор.Группы[0]
+
+	var bx, by bytes.Buffer
+	assert.Nil(t, WriteCanonical(x, &bx))
+	assert.Nil(t, WriteCanonical(y, &by))
+	assert.Equal(t, bx.String(), by.String())
+}
+
+func TestHash(t *testing.T) {
+	x, y := snapshot(), snapshot()
+	x.ДатаФормирования = "2019-07-01T10:15:42"
+	y.ДатаФормирования = "2019-07-02T10:15:42"
+	y.ПакетПредложений.Предложения[0], y.ПакетПредложений.Предложения[2] = y.ПакетПредложений.Предложения[2], y.ПакетПредложений.Предложения[0]
+
+	hx, err := Hash(x)
+	assert.Nil(t, err)
+	hy, err := Hash(y)
+	assert.Nil(t, err)
+	assert.Equal(t, hx, hy)
+	assert.Equal(t, 64, len(hx))
+
+	y.ПакетПредложений.Предложения[0].Количество = "5"
+	hy, err = Hash(y)
+	assert.Nil(t, err)
+	assert.NotEqual(t, hx, hy)
+}
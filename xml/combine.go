@@ -0,0 +1,247 @@
+package xml
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Conflicts of combined documents
+type Conflicts []string
+
+func (c Conflicts) Error() string {
+	return "combine: " + strings.Join(c, "; ")
+}
+
+func (c *Conflicts) add(format string, args ...interface{}) {
+	*c = append(*c, fmt.Sprintf(format, args...))
+}
+
+// Combine joins parts of one export (import0_1.xml, import1_1.xml, offers0_1.xml...)
+// into single document. Groups, properties, products, price types and warehouses
+// are collected by Ид, offers split over several files are joined field by field
+// with prices by ИдТипаЦены and stock by ИдСклада.
+// ИзменениеПакетаПредложений (prices*.xml, rests*.xml of 3.x) is joined into ПакетПредложений.
+// Different values of the same element are reported as Conflicts.
+// Unlike Merge, no part overrides another one
+func Combine(docs ...*КоммерческаяИнформация) (*КоммерческаяИнформация, error) {
+	var (
+		x         = new(КоммерческаяИнформация)
+		conflicts Conflicts
+		groups    []GroupRow
+		groupIdx  = make(map[string]int)
+		props     = make(map[string]int)
+		products  = make(map[string]int)
+		types     = make(map[string]int)
+		stores    = make(map[string]int)
+		offers    = make(map[string]int)
+	)
+
+	for _, doc := range docs {
+		if doc == nil {
+			continue
+		}
+		sameValue(&conflicts, "ВерсияСхемы", &x.ВерсияСхемы, doc.ВерсияСхемы)
+		if doc.ДатаФормирования > x.ДатаФормирования {
+			x.ДатаФормирования = doc.ДатаФормирования
+		}
+		x.СинхронизацияТоваров = x.СинхронизацияТоваров || doc.СинхронизацияТоваров
+
+		if k := doc.Классификатор; k != nil {
+			if x.Классификатор == nil {
+				x.Классификатор = &Классификатор{}
+			}
+			c := x.Классификатор
+			sameValue(&conflicts, "Классификатор Ид", &c.Ид, k.Ид)
+			sameValue(&conflicts, "Классификатор Наименование", &c.Наименование, k.Наименование)
+			sameValue(&conflicts, "Классификатор Владелец", &c.Владелец, k.Владелец)
+			for _, row := range k.Flatten() {
+				if idx, found := groupIdx[row.Ид]; !found {
+					groupIdx[row.Ид] = len(groups)
+					groups = append(groups, row)
+				} else if groups[idx].ParentИд != row.ParentИд ||
+					!reflect.DeepEqual(groups[idx].Группа, row.Группа) {
+					conflicts.add("Группа %s differs", row.Ид)
+				}
+			}
+			for _, p := range k.Properties() {
+				if idx, found := props[p.Ид]; !found {
+					list := c.Properties()
+					props[p.Ид] = len(list)
+					list = append(list, p)
+					c.Свойства = &list
+				} else if !reflect.DeepEqual(c.Properties()[idx], p) {
+					conflicts.add("Свойство %s differs", p.Ид)
+				}
+			}
+		}
+
+		if k := doc.Каталог; k != nil {
+			if x.Каталог == nil {
+				x.Каталог = &Каталог{СодержитТолькоИзменения: k.СодержитТолькоИзменения}
+			}
+			c := x.Каталог
+			sameValue(&conflicts, "Каталог Ид", &c.Ид, k.Ид)
+			sameValue(&conflicts, "Каталог ИдКлассификатора", &c.ИдКлассификатора, k.ИдКлассификатора)
+			sameValue(&conflicts, "Каталог Наименование", &c.Наименование, k.Наименование)
+			for _, p := range k.Товары {
+				if idx, found := products[p.Ид]; !found {
+					products[p.Ид] = len(c.Товары)
+					c.Товары = append(c.Товары, p)
+				} else if !reflect.DeepEqual(c.Товары[idx], p) {
+					conflicts.add("Товар %s differs", p.Ид)
+				}
+			}
+		}
+
+		if pack := doc.ПакетПредложений; pack != nil {
+			if x.ПакетПредложений == nil {
+				x.ПакетПредложений = &ПакетПредложений{СодержитТолькоИзменения: pack.СодержитТолькоИзменения}
+			}
+			combineOffers(&conflicts, x.ПакетПредложений, pack, types, stores, offers)
+		}
+
+		// prices and rests of 3.x are joined into offers
+		if change := doc.ИзменениеПакетаПредложений; change != nil {
+			if x.ПакетПредложений == nil {
+				x.ПакетПредложений = &ПакетПредложений{СодержитТолькоИзменения: change.СодержитТолькоИзменения}
+			}
+			pack := &ПакетПредложений{
+				Ид:               change.Ид,
+				ИдКаталога:       change.ИдКаталога,
+				ИдКлассификатора: change.ИдКлассификатора,
+			}
+			for idx := range change.Предложения {
+				pack.Предложения = append(pack.Предложения, change.Предложения[idx].Offer())
+			}
+			combineOffers(&conflicts, x.ПакетПредложений, pack, types, stores, offers)
+		}
+
+		x.Документ = append(x.Документ, doc.Документ...)
+	}
+
+	if x.Классификатор != nil {
+		x.Классификатор.Группы = BuildGroups(groups)
+	}
+	if len(conflicts) > 0 {
+		return nil, conflicts
+	}
+	return x, nil
+}
+
+func combineOffers(conflicts *Conflicts, x, pack *ПакетПредложений, types, stores, offers map[string]int) {
+	sameValue(conflicts, "ПакетПредложений Ид", &x.Ид, pack.Ид)
+	sameValue(conflicts, "ПакетПредложений Наименование", &x.Наименование, pack.Наименование)
+	sameValue(conflicts, "ПакетПредложений ИдКаталога", &x.ИдКаталога, pack.ИдКаталога)
+	sameValue(conflicts, "ПакетПредложений ИдКлассификатора", &x.ИдКлассификатора, pack.ИдКлассификатора)
+	sameValue(conflicts, "ПакетПредложений Владелец", &x.Владелец, pack.Владелец)
+
+	for _, t := range pack.ТипыЦен {
+		if idx, found := types[t.Ид]; !found {
+			types[t.Ид] = len(x.ТипыЦен)
+			x.ТипыЦен = append(x.ТипыЦен, t)
+		} else if !reflect.DeepEqual(x.ТипыЦен[idx], t) {
+			conflicts.add("ТипЦены %s differs", t.Ид)
+		}
+	}
+	for _, s := range pack.Склады {
+		if idx, found := stores[s.Ид]; !found {
+			stores[s.Ид] = len(x.Склады)
+			x.Склады = append(x.Склады, s)
+		} else if !reflect.DeepEqual(x.Склады[idx], s) {
+			conflicts.add("Склад %s differs", s.Ид)
+		}
+	}
+
+	for _, o := range pack.Предложения {
+		idx, found := offers[o.Ид]
+		if !found {
+			offers[o.Ид] = len(x.Предложения)
+			o.Цены = append([]Цена(nil), o.Цены...)
+			o.Склад = append([]Остаток(nil), o.Склад...)
+			x.Предложения = append(x.Предложения, o)
+			continue
+		}
+		combineOffer(conflicts, &x.Предложения[idx], o)
+	}
+}
+
+// combineOffer fills empty fields of dst by src
+func combineOffer(conflicts *Conflicts, dst *Предложение, src Предложение) {
+	sameValue(conflicts, "Предложение "+dst.Ид+" Артикул", &dst.Артикул, src.Артикул)
+	sameValue(conflicts, "Предложение "+dst.Ид+" Наименование", &dst.Наименование, src.Наименование)
+	sameValue(conflicts, "Предложение "+dst.Ид+" БазоваяЕдиница", &dst.БазоваяЕдиница, src.БазоваяЕдиница)
+	sameValue(conflicts, "Предложение "+dst.Ид+" ХарактеристикиТовара",
+		&dst.ХарактеристикиТовара, src.ХарактеристикиТовара)
+	sameValue(conflicts, "Предложение "+dst.Ид+" Количество", &dst.Количество, src.Количество)
+
+next:
+	for _, price := range src.Цены {
+		for _, p := range dst.Цены {
+			if p.ИдТипаЦены == price.ИдТипаЦены {
+				if !reflect.DeepEqual(p, price) {
+					conflicts.add("Предложение %s Цена %s differs", dst.Ид, price.ИдТипаЦены)
+				}
+				continue next
+			}
+		}
+		dst.Цены = append(dst.Цены, price)
+	}
+
+nextStock:
+	for _, rest := range src.Склад {
+		for _, r := range dst.Склад {
+			if r.ИдСклада == rest.ИдСклада {
+				if r.КоличествоНаСкладе != rest.КоличествоНаСкладе {
+					conflicts.add("Предложение %s Склад %s differs", dst.Ид, rest.ИдСклада)
+				}
+				continue nextStock
+			}
+		}
+		dst.Склад = append(dst.Склад, rest)
+	}
+}
+
+// sameValue sets zero *dst to src or reports different non zero values
+func sameValue(conflicts *Conflicts, what string, dst, src interface{}) {
+	d := reflect.ValueOf(dst).Elem()
+	s := reflect.ValueOf(src)
+	switch {
+	case isZero(s):
+	case isZero(d):
+		d.Set(s)
+	case !reflect.DeepEqual(d.Interface(), src):
+		conflicts.add("%s differs", what)
+	}
+}
+
+func isZero(v reflect.Value) bool {
+	return reflect.DeepEqual(v.Interface(), reflect.Zero(v.Type()).Interface())
+}
+
+// CombineDir reads *.xml files of dir in order of names (import*, offers*...)
+// and combines them
+func CombineDir(dir string) (*КоммерческаяИнформация, error) {
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, info := range infos {
+		if !info.IsDir() && strings.EqualFold(filepath.Ext(info.Name()), ".xml") {
+			names = append(names, info.Name())
+		}
+	}
+	sort.Strings(names)
+
+	docs := make([]*КоммерческаяИнформация, len(names))
+	for idx, name := range names {
+		if docs[idx], err = ReadFile(filepath.Join(dir, name)); err != nil {
+			return nil, fmt.Errorf("%s: %v", name, err)
+		}
+	}
+	return Combine(docs...)
+}
@@ -0,0 +1,119 @@
+package xml
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCombineSplit(t *testing.T) {
+	x := large(25)
+	parts, err := Split(x, SplitLimits{Products: 7})
+	assert.Nil(t, err)
+
+	combined, err := Combine(parts...)
+	assert.Nil(t, err)
+	assert.Equal(t, x, combined)
+}
+
+func TestCombineOffers(t *testing.T) {
+	prices := &КоммерческаяИнформация{ВерсияСхемы: "2.05", ДатаФормирования: "2019-07-01T10:00:00",
+		ПакетПредложений: &ПакетПредложений{Ид: "pack",
+			ТипыЦен: []ТипЦены{{Ид: "retail"}},
+			Предложения: []Предложение{
+				{Ид: "p1", Наименование: "Брюки", Цены: []Цена{{ИдТипаЦены: "retail", ЦенаЗаЕдиницу: "100"}}},
+			}}}
+	rests := &КоммерческаяИнформация{ДатаФормирования: "2019-07-01T10:05:00",
+		ПакетПредложений: &ПакетПредложений{Ид: "pack",
+			Склады: []Склад{{Ид: "w1"}},
+			Предложения: []Предложение{
+				{Ид: "p1", Количество: "3", Склад: []Остаток{{ИдСклада: "w1", КоличествоНаСкладе: "3"}}},
+				{Ид: "p2", Количество: "1"},
+			}}}
+
+	x, err := Combine(prices, nil, rests)
+	assert.Nil(t, err)
+	assert.Equal(t, "2.05", x.ВерсияСхемы)
+	assert.Equal(t, "2019-07-01T10:05:00", x.ДатаФормирования)
+	assert.Nil(t, x.Каталог)
+	assert.Equal(t, 1, len(x.ПакетПредложений.ТипыЦен))
+	assert.Equal(t, 1, len(x.ПакетПредложений.Склады))
+	assert.Equal(t, []Предложение{
+		{Ид: "p1", Наименование: "Брюки", Количество: "3",
+			Цены:  []Цена{{ИдТипаЦены: "retail", ЦенаЗаЕдиницу: "100"}},
+			Склад: []Остаток{{ИдСклада: "w1", КоличествоНаСкладе: "3"}}},
+		{Ид: "p2", Количество: "1"},
+	}, x.ПакетПредложений.Предложения)
+
+	// sources are not modified
+	assert.Equal(t, 0, len(prices.ПакетПредложений.Предложения[0].Склад))
+}
+
+func TestCombineConflicts(t *testing.T) {
+	a, b := snapshot(), snapshot()
+	b.ВерсияСхемы = "2.05"
+	b.Классификатор.Группы[1].Наименование = "Сапоги"
+	b.Каталог.Товары[0].Наименование = "Джинсы"
+	b.ПакетПредложений.Предложения[1].Цены[0].ЦенаЗаЕдиницу = "250"
+	b.ПакетПредложений.Предложения[2].Количество = "0"
+
+	_, err := Combine(a, b)
+	conflicts, ok := err.(Conflicts)
+	assert.True(t, ok)
+	assert.Equal(t, Conflicts{
+		"ВерсияСхемы differs",
+		"Группа g2 differs",
+		"Товар p1 differs",
+		"Предложение p2 Цена retail differs",
+		"Предложение p3 Количество differs",
+	}, conflicts)
+	assert.Contains(t, err.Error(), "combine: ВерсияСхемы differs; ")
+
+	b = snapshot()
+	b.Каталог.Ид = "other"
+	_, err = Combine(a, b)
+	assert.Equal(t, Conflicts{"Каталог Ид differs"}, err)
+
+	// equal parts are fine
+	x, err := Combine(snapshot(), snapshot())
+	assert.Nil(t, err)
+	assert.Equal(t, snapshot(), x)
+}
+
+func TestCombineDir(t *testing.T) {
+	dir, err := ioutil.TempDir("", "combine")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	x := large(10)
+	_, err = WriteSplit(x, SplitLimits{Products: 3}, dir)
+	assert.Nil(t, err)
+	assert.Nil(t, ioutil.WriteFile(dir+"/readme.txt", []byte("not xml"), 0644))
+
+	combined, err := CombineDir(dir)
+	assert.Nil(t, err)
+	assert.Equal(t, x, combined)
+
+	_, err = CombineDir(dir + "/unknown")
+	assert.NotNil(t, err)
+}
+
+func TestCombineChanges(t *testing.T) {
+	x, err := Combine(ReadMust("testdata/prices.xml"), ReadMust("testdata/rests.xml"))
+	assert.Nil(t, err)
+	assert.Nil(t, x.ИзменениеПакетаПредложений)
+
+	pack := x.ПакетПредложений
+	assert.True(t, pack.СодержитТолькоИзменения)
+	assert.Equal(t, "3d6c8e2a-9b1f-11e9-80d4-0cc47a7c2f11#", pack.Ид)
+	assert.Equal(t, 2, len(pack.Предложения))
+	assert.Equal(t, 2, len(pack.Предложения[0].Цены))
+	assert.Equal(t, 2, len(pack.Предложения[0].Склад))
+	assert.Equal(t, "7", pack.Предложения[0].Количество)
+
+	// prices of offers.xml differ
+	_, err = Combine(ReadMust("testdata/offers.xml"), ReadMust("testdata/prices.xml"))
+	assert.NotNil(t, err)
+}
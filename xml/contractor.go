@@ -0,0 +1,94 @@
+package xml
+
+import (
+	"strings"
+)
+
+// addressOrder is the order of АдресноеПоле types in formatted address
+var addressOrder = []string{
+	"Почтовый индекс",
+	"Страна",
+	"Регион",
+	"Район",
+	"Населенный пункт",
+	"Город",
+	"Улица",
+	"Дом",
+	"Корпус",
+	"Квартира",
+}
+
+// Field returns Значение of АдресноеПоле by Тип
+func (a *Адрес) Field(typ string) (string, bool) {
+	for _, f := range a.АдресноеПоле {
+		if f.Тип == typ {
+			return f.Значение, true
+		}
+	}
+	return "", false
+}
+
+// Format joins non empty АдресноеПоле from postal code to apartment,
+// fields of unknown types follow in order of document
+func (a *Адрес) Format() string {
+	var parts []string
+	known := make(map[string]bool)
+	for _, typ := range addressOrder {
+		known[typ] = true
+		if v, found := a.Field(typ); found && len(strings.TrimSpace(v)) > 0 {
+			parts = append(parts, strings.TrimSpace(v))
+		}
+	}
+	for _, f := range a.АдресноеПоле {
+		if !known[f.Тип] && len(strings.TrimSpace(f.Значение)) > 0 {
+			parts = append(parts, strings.TrimSpace(f.Значение))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// String returns Представление or formatted АдресноеПоле if there is no one
+func (a *Адрес) String() string {
+	if a == nil {
+		return ""
+	}
+	if len(a.Представление) > 0 {
+		return a.Представление
+	}
+	return a.Format()
+}
+
+// Contact returns Значение of Контакт by Тип
+func (k *Контрагент) Contact(typ string) (string, bool) {
+	if k.Контакты == nil {
+		return "", false
+	}
+	for _, c := range *k.Контакты {
+		if c.Тип == typ {
+			return c.Значение, true
+		}
+	}
+	return "", false
+}
+
+// Address returns Адрес or ЮридическийАдрес if there is no one
+func (k *Контрагент) Address() *Адрес {
+	if k.Адрес != nil {
+		return k.Адрес
+	}
+	return k.ЮридическийАдрес
+}
+
+// Contractor returns Контрагент of document by Роль or nil
+func (d *Документ) Contractor(role string) *Контрагент {
+	if d.Контрагенты == nil {
+		return nil
+	}
+	contractors := *d.Контрагенты
+	for idx := range contractors {
+		if contractors[idx].Роль == role {
+			return &contractors[idx]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+package xml
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const order = `<?xml version="1.0" encoding="UTF-8"?>
+<КоммерческаяИнформация ВерсияСхемы="2.05" ДатаФормирования="2019-03-01T10:00:00">
+  <Документ>
+    <Ид>42</Ид>
+    <Номер>42</Номер>
+    <Дата>2019-03-01</Дата>
+    <ХозОперация>Заказ товара</ХозОперация>
+    <Роль>Продавец</Роль>
+    <Валюта>руб</Валюта>
+    <Курс>1</Курс>
+    <Сумма>2500</Сумма>
+    <Контрагенты>
+      <Контрагент>
+        <Ид>7#ivanov</Ид>
+        <Наименование>Иванов Иван</Наименование>
+        <Роль>Покупатель</Роль>
+        <ПолноеНаименование>Иванов Иван Иванович</ПолноеНаименование>
+        <ОКПО>12345678</ОКПО>
+        <РасчетныеСчета>
+          <РасчетныйСчет>
+            <НомерСчета>40702810000000000001</НомерСчета>
+            <Банк>
+              <Наименование>Банк</Наименование>
+              <СчетКорреспондентский>30101810400000000225</СчетКорреспондентский>
+              <БИК>044525225</БИК>
+            </Банк>
+          </РасчетныйСчет>
+        </РасчетныеСчета>
+        <Адрес>
+          <АдресноеПоле><Тип>Улица</Тип><Значение>Ленина</Значение></АдресноеПоле>
+          <АдресноеПоле><Тип>Почтовый индекс</Тип><Значение>123456</Значение></АдресноеПоле>
+          <АдресноеПоле><Тип>Город</Тип><Значение>Москва</Значение></АдресноеПоле>
+          <АдресноеПоле><Тип>Дом</Тип><Значение>1</Значение></АдресноеПоле>
+          <АдресноеПоле><Тип>Подъезд</Тип><Значение>2</Значение></АдресноеПоле>
+        </Адрес>
+        <Контакты>
+          <Контакт><Тип>Почта</Тип><Значение>ivanov@example.com</Значение></Контакт>
+        </Контакты>
+        <Представители>
+          <Представитель>
+            <Отношение>Контактное лицо</Отношение>
+            <Ид>b7</Ид>
+            <Наименование>Петров Петр</Наименование>
+          </Представитель>
+        </Представители>
+      </Контрагент>
+    </Контрагенты>
+    <Время>10:00:00</Время>
+    <Товары>
+      <Товар>
+        <Ид>c5e4a1b0</Ид>
+        <Наименование>Платье летнее</Наименование>
+        <БазоваяЕдиница Код="796" НаименованиеПолное="Штука">шт</БазоваяЕдиница>
+        <ЦенаЗаЕдиницу>2500</ЦенаЗаЕдиницу>
+        <Количество>1</Количество>
+        <Сумма>2500</Сумма>
+      </Товар>
+    </Товары>
+  </Документ>
+</КоммерческаяИнформация>`
+
+func TestOrderContractor(t *testing.T) {
+	x, err := Read(bytes.NewBufferString(order))
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(x.Документ))
+
+	d := &x.Документ[0]
+	assert.Nil(t, d.Contractor("Продавец"))
+	k := d.Contractor("Покупатель")
+	assert.Equal(t, "Иванов Иван Иванович", k.ПолноеНаименование)
+	assert.Equal(t, "12345678", k.ОКПО)
+	assert.Equal(t, "044525225", (*k.РасчетныеСчета)[0].Банк.БИК)
+	assert.Equal(t, "Петров Петр", (*k.Представители)[0].Наименование)
+
+	mail, found := k.Contact("Почта")
+	assert.True(t, found)
+	assert.Equal(t, "ivanov@example.com", mail)
+	_, found = k.Contact("Телефон рабочий")
+	assert.False(t, found)
+
+	street, found := k.Address().Field("Улица")
+	assert.True(t, found)
+	assert.Equal(t, "Ленина", street)
+	assert.Equal(t, "123456, Москва, Ленина, 1, 2", k.Address().String())
+
+	assert.Equal(t, "Платье летнее", (*d.Товары)[0].Наименование)
+
+	buf := new(bytes.Buffer)
+	assert.Nil(t, Write(x, buf))
+	y, err := Read(buf)
+	assert.Nil(t, err)
+	assert.Equal(t, x, y)
+
+	// absent lists are not written as empty parents
+	buf.Reset()
+	assert.Nil(t, Write(&КоммерческаяИнформация{Документ: []Документ{{Ид: "d"}}}, buf))
+	for _, list := range []string{"Контрагенты", "Товары", "ЗначенияРеквизитов"} {
+		assert.NotContains(t, buf.String(), list)
+	}
+	buf.Reset()
+	assert.Nil(t, Write(&КоммерческаяИнформация{Классификатор: &Классификатор{}}, buf))
+	for _, list := range []string{"РасчетныеСчета", "Представители"} {
+		assert.NotContains(t, buf.String(), list)
+	}
+}
+
+func TestAddress(t *testing.T) {
+	var a *Адрес
+	assert.Equal(t, "", a.String())
+
+	a = &Адрес{Представление: "123456, Москва г, Ленина ул, дом № 1"}
+	assert.Equal(t, "123456, Москва г, Ленина ул, дом № 1", a.String())
+	assert.Equal(t, "", a.Format())
+
+	owner := ReadMust("testdata/import.xml").Классификатор.Владелец
+	assert.Equal(t, `ООО "Торговый дом"`, owner.ОфициальноеНаименование)
+	assert.Equal(t, "123456, Москва г, Ленина ул, дом № 1", owner.Address().String())
+	assert.Nil(t, (&Контрагент{}).Address())
+}
@@ -0,0 +1,192 @@
+package xml
+
+import (
+	"reflect"
+)
+
+type (
+	// Changes lists Ид of added, changed and removed elements
+	Changes struct {
+		Added   []string `json:"added,omitempty"`
+		Changed []string `json:"changed,omitempty"`
+		Removed []string `json:"removed,omitempty"`
+	}
+
+	// Summary of changes between two documents
+	Summary struct {
+		Groups   Changes `json:"groups"`
+		Products Changes `json:"products"`
+		Offers   Changes `json:"offers"`
+	}
+)
+
+// Empty reports whether there are no changes
+func (c *Changes) Empty() bool {
+	return len(c.Added) == 0 && len(c.Changed) == 0 && len(c.Removed) == 0
+}
+
+// Empty reports whether there are no changes at all
+func (s *Summary) Empty() bool {
+	return s.Groups.Empty() && s.Products.Empty() && s.Offers.Empty()
+}
+
+// Diff compares prev and next snapshots and returns document with
+// СодержитТолькоИзменения containing only added and changed groups, products and offers.
+// Removed products are included with ПометкаУдаления, removed groups and offers
+// as well as groups moved to the top level can not be expressed in document
+// and are listed in summary only
+func Diff(prev, next *КоммерческаяИнформация) (*КоммерческаяИнформация, *Summary) {
+	if prev == nil {
+		prev = new(КоммерческаяИнформация)
+	}
+	if next == nil {
+		next = new(КоммерческаяИнформация)
+	}
+
+	s := new(Summary)
+	x := &КоммерческаяИнформация{
+		ВерсияСхемы:          next.ВерсияСхемы,
+		ДатаФормирования:     next.ДатаФормирования,
+		СинхронизацияТоваров: next.СинхронизацияТоваров,
+	}
+
+	if next.Классификатор != nil {
+		x.Классификатор = diffClassifier(prev.Классификатор, next.Классификатор, &s.Groups)
+	} else if prev.Классификатор != nil {
+		for _, row := range prev.Классификатор.Flatten() {
+			s.Groups.Removed = append(s.Groups.Removed, row.Ид)
+		}
+	}
+
+	if next.Каталог != nil {
+		x.Каталог = diffCatalog(prev.Каталог, next.Каталог, &s.Products)
+	} else if prev.Каталог != nil {
+		for _, p := range prev.Каталог.Товары {
+			s.Products.Removed = append(s.Products.Removed, p.Ид)
+		}
+	}
+
+	if next.ПакетПредложений != nil {
+		x.ПакетПредложений = diffOffers(prev.ПакетПредложений, next.ПакетПредложений, &s.Offers)
+	} else if prev.ПакетПредложений != nil {
+		for _, o := range prev.ПакетПредложений.Предложения {
+			s.Offers.Removed = append(s.Offers.Removed, o.Ид)
+		}
+	}
+
+	return x, s
+}
+
+func diffClassifier(prev, next *Классификатор, c *Changes) *Классификатор {
+	var prevRows []GroupRow
+	if prev != nil {
+		prevRows = prev.Flatten()
+	}
+	nextRows := next.Flatten()
+
+	// group is changed by its own fields or parent, not by path
+	old := make(map[string]GroupRow, len(prevRows))
+	for _, row := range prevRows {
+		old[row.Ид] = row
+	}
+	keep := make(map[string]bool)
+	for _, row := range nextRows {
+		o, found := old[row.Ид]
+		switch {
+		case !found:
+			c.Added = append(c.Added, row.Ид)
+		case o.ParentИд != row.ParentИд || !reflect.DeepEqual(o.Группа, row.Группа):
+			c.Changed = append(c.Changed, row.Ид)
+		default:
+			continue
+		}
+		keep[row.Ид] = true
+	}
+	for _, row := range nextRows {
+		delete(old, row.Ид)
+	}
+	for _, row := range prevRows {
+		if _, found := old[row.Ид]; found {
+			c.Removed = append(c.Removed, row.Ид)
+		}
+	}
+
+	x := *next
+	x.Prune(keep)
+	return &x
+}
+
+func diffCatalog(prev, next *Каталог, c *Changes) *Каталог {
+	old := make(map[string]*Товар)
+	if prev != nil {
+		for idx := range prev.Товары {
+			old[prev.Товары[idx].Ид] = &prev.Товары[idx]
+		}
+	}
+
+	x := *next
+	x.СодержитТолькоИзменения = true
+	x.Товары = nil
+
+	seen := make(map[string]bool)
+	for _, p := range next.Товары {
+		seen[p.Ид] = true
+		o, found := old[p.Ид]
+		switch {
+		case !found:
+			c.Added = append(c.Added, p.Ид)
+		case !reflect.DeepEqual(*o, p):
+			c.Changed = append(c.Changed, p.Ид)
+		default:
+			continue
+		}
+		x.Товары = append(x.Товары, p)
+	}
+	if prev != nil {
+		for _, p := range prev.Товары {
+			if !seen[p.Ид] {
+				c.Removed = append(c.Removed, p.Ид)
+				p.ПометкаУдаления, p.Статус = true, СтатусУдален
+				x.Товары = append(x.Товары, p)
+			}
+		}
+	}
+	return &x
+}
+
+func diffOffers(prev, next *ПакетПредложений, c *Changes) *ПакетПредложений {
+	old := make(map[string]*Предложение)
+	if prev != nil {
+		for idx := range prev.Предложения {
+			old[prev.Предложения[idx].Ид] = &prev.Предложения[idx]
+		}
+	}
+
+	// ТипыЦен and Склады are kept as is, offers refer to them
+	x := *next
+	x.СодержитТолькоИзменения = true
+	x.Предложения = nil
+
+	seen := make(map[string]bool)
+	for _, o := range next.Предложения {
+		seen[o.Ид] = true
+		p, found := old[o.Ид]
+		switch {
+		case !found:
+			c.Added = append(c.Added, o.Ид)
+		case !reflect.DeepEqual(*p, o):
+			c.Changed = append(c.Changed, o.Ид)
+		default:
+			continue
+		}
+		x.Предложения = append(x.Предложения, o)
+	}
+	if prev != nil {
+		for _, o := range prev.Предложения {
+			if !seen[o.Ид] {
+				c.Removed = append(c.Removed, o.Ид)
+			}
+		}
+	}
+	return &x
+}
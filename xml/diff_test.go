@@ -0,0 +1,114 @@
+package xml
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func snapshot() *КоммерческаяИнформация {
+	return &КоммерческаяИнформация{
+		ВерсияСхемы: "2.04",
+		Классификатор: &Классификатор{
+			Ид: "cls",
+			Группы: []Группа{
+				{Ид: "g1", Наименование: "Одежда", Группы: &[]Группа{
+					{Ид: "g11", Наименование: "Мужская"},
+					{Ид: "g12", Наименование: "Женская"},
+				}},
+				{Ид: "g2", Наименование: "Обувь"},
+			},
+		},
+		Каталог: &Каталог{
+			Ид: "cat",
+			Товары: []Товар{
+				{Ид: "p1", Наименование: "Брюки", Группы: []string{"g11"}},
+				{Ид: "p2", Наименование: "Юбка", Группы: []string{"g12"}},
+				{Ид: "p3", Наименование: "Кеды", Группы: []string{"g2"}},
+			},
+		},
+		ПакетПредложений: &ПакетПредложений{
+			Ид:      "pack",
+			ТипыЦен: []ТипЦены{{Ид: "retail", Наименование: "Розничная"}},
+			Предложения: []Предложение{
+				{Ид: "p1", Количество: "1", Цены: []Цена{{ИдТипаЦены: "retail", ЦенаЗаЕдиницу: "100"}}},
+				{Ид: "p2", Количество: "2", Цены: []Цена{{ИдТипаЦены: "retail", ЦенаЗаЕдиницу: "200"}}},
+				{Ид: "p3", Количество: "3", Цены: []Цена{{ИдТипаЦены: "retail", ЦенаЗаЕдиницу: "300"}}},
+			},
+		},
+	}
+}
+
+func TestDiffNoChanges(t *testing.T) {
+	x, s := Diff(snapshot(), snapshot())
+	assert.True(t, s.Empty())
+	assert.Equal(t, 0, len(x.Классификатор.Группы))
+	assert.Equal(t, 0, len(x.Каталог.Товары))
+	assert.True(t, x.Каталог.СодержитТолькоИзменения)
+	assert.Equal(t, 0, len(x.ПакетПредложений.Предложения))
+	assert.True(t, x.ПакетПредложений.СодержитТолькоИзменения)
+	assert.Equal(t, 1, len(x.ПакетПредложений.ТипыЦен))
+}
+
+func TestDiff(t *testing.T) {
+	prev, next := snapshot(), snapshot()
+
+	(*next.Классификатор.Группы[0].Группы)[1].Наименование = "Для женщин"
+	next.Классификатор.Группы = append(next.Классификатор.Группы, Группа{Ид: "g3", Наименование: "Сумки"})
+	next.Классификатор.Группы[1].Группы = &[]Группа{{Ид: "g21", Наименование: "Детская"}}
+
+	next.Каталог.Товары[0].Наименование = "Брюки мужские"
+	next.Каталог.Товары = append(next.Каталог.Товары[:2], Товар{Ид: "p4", Наименование: "Сумка"})
+
+	next.ПакетПредложений.Предложения[1].Цены[0].ЦенаЗаЕдиницу = "250"
+	next.ПакетПредложений.Предложения = next.ПакетПредложений.Предложения[:2]
+
+	x, s := Diff(prev, next)
+
+	assert.Equal(t, []string{"g21", "g3"}, s.Groups.Added)
+	assert.Equal(t, []string{"g12"}, s.Groups.Changed)
+	assert.Nil(t, s.Groups.Removed)
+
+	assert.Equal(t, []string{"p4"}, s.Products.Added)
+	assert.Equal(t, []string{"p1"}, s.Products.Changed)
+	assert.Equal(t, []string{"p3"}, s.Products.Removed)
+
+	assert.Nil(t, s.Offers.Added)
+	assert.Equal(t, []string{"p2"}, s.Offers.Changed)
+	assert.Equal(t, []string{"p3"}, s.Offers.Removed)
+
+	// changed groups come with their ancestors
+	groups := x.Классификатор.Группы
+	assert.Equal(t, 3, len(groups))
+	assert.Equal(t, "g1", groups[0].Ид)
+	assert.Equal(t, 1, len(*groups[0].Группы))
+	assert.Equal(t, "Для женщин", (*groups[0].Группы)[0].Наименование)
+	assert.Equal(t, "g2", groups[1].Ид)
+	assert.Equal(t, "g21", (*groups[1].Группы)[0].Ид)
+	assert.Equal(t, "g3", groups[2].Ид)
+
+	assert.Equal(t, 3, len(x.Каталог.Товары))
+	assert.Equal(t, "p1", x.Каталог.Товары[0].Ид)
+	assert.Equal(t, "p4", x.Каталог.Товары[1].Ид)
+	assert.Equal(t, "p3", x.Каталог.Товары[2].Ид)
+	assert.True(t, x.Каталог.Товары[2].Deleted())
+	assert.False(t, prev.Каталог.Товары[2].Deleted())
+
+	assert.Equal(t, 1, len(x.ПакетПредложений.Предложения))
+	assert.Equal(t, "250", x.ПакетПредложений.Предложения[0].Цены[0].ЦенаЗаЕдиницу)
+
+	// source documents are not modified
+	assert.Equal(t, 3, len(prev.Каталог.Товары))
+	assert.Equal(t, 2, len(*next.Классификатор.Группы[0].Группы))
+}
+
+func TestDiffMissingSections(t *testing.T) {
+	x, s := Diff(nil, snapshot())
+	assert.Equal(t, []string{"g1", "g11", "g12", "g2"}, s.Groups.Added)
+	assert.Equal(t, 3, len(x.Каталог.Товары))
+
+	x, s = Diff(snapshot(), &КоммерческаяИнформация{})
+	assert.Nil(t, x.Каталог)
+	assert.Equal(t, []string{"p1", "p2", "p3"}, s.Products.Removed)
+	assert.Equal(t, []string{"g1", "g11", "g12", "g2"}, s.Groups.Removed)
+}
@@ -0,0 +1,207 @@
+package xml
+
+import (
+	"errors"
+	"fmt"
+)
+
+type (
+	// WalkFunc is called for every group with its ancestors from the top level
+	WalkFunc func(g *Группа, parents []*Группа) error
+
+	// GroupRow is the group of flat tree
+	GroupRow struct {
+		Группа            // without subgroups
+		ParentИд string   // empty for top level group
+		Path     []string // names from the top level group
+	}
+)
+
+// SkipGroups returned by WalkFunc skips subgroups of group
+var SkipGroups = errors.New("skip subgroups")
+
+// Walk calls fn for groups in tree order (parent before subgroups).
+// Walk stops on first error of fn except SkipGroups
+func (k *Классификатор) Walk(fn WalkFunc) error {
+	return walkGroups(k.Группы, nil, fn)
+}
+
+func walkGroups(groups []Группа, parents []*Группа, fn WalkFunc) error {
+	for idx := range groups {
+		g := &groups[idx]
+		err := fn(g, parents)
+		if err == SkipGroups {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if g.Группы != nil {
+			path := append(parents[:len(parents):len(parents)], g)
+			if err := walkGroups(*g.Группы, path, fn); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Find returns group by Ид or nil
+func (k *Классификатор) Find(id string) *Группа {
+	g, _ := k.find(id)
+	return g
+}
+
+// Parent returns parent of group, nil for top level one.
+// found is false if there is no such group
+func (k *Классификатор) Parent(id string) (parent *Группа, found bool) {
+	g, parent := k.find(id)
+	return parent, g != nil
+}
+
+var errFound = errors.New("found")
+
+func (k *Классификатор) find(id string) (group *Группа, parent *Группа) {
+	k.Walk(func(g *Группа, parents []*Группа) error {
+		if g.Ид != id {
+			return nil
+		}
+		group = g
+		if len(parents) > 0 {
+			parent = parents[len(parents)-1]
+		}
+		return errFound
+	})
+	return
+}
+
+// Flatten returns groups in tree order
+func (k *Классификатор) Flatten() []GroupRow {
+	var rows []GroupRow
+	k.Walk(func(g *Группа, parents []*Группа) error {
+		row := GroupRow{Группа: *g, Path: make([]string, 0, len(parents)+1)}
+		row.Группы = nil
+		for _, p := range parents {
+			row.Path = append(row.Path, p.Наименование)
+		}
+		row.Path = append(row.Path, g.Наименование)
+		if len(parents) > 0 {
+			row.ParentИд = parents[len(parents)-1].Ид
+		}
+		rows = append(rows, row)
+		return nil
+	})
+	return rows
+}
+
+// BuildGroups makes tree of flat groups. Children follow order of rows,
+// groups with unknown parent become top level. Path of rows is ignored
+func BuildGroups(rows []GroupRow) []Группа {
+	known := make(map[string]bool, len(rows))
+	for _, row := range rows {
+		known[row.Ид] = true
+	}
+	children := make(map[string][]int)
+	for idx, row := range rows {
+		parent := row.ParentИд
+		if !known[parent] || parent == row.Ид {
+			parent = ""
+		}
+		children[parent] = append(children[parent], idx)
+	}
+
+	visited := make(map[string]bool, len(rows))
+	var build func(parent string) []Группа
+	build = func(parent string) []Группа {
+		var groups []Группа
+		for _, idx := range children[parent] {
+			g := rows[idx].Группа
+			g.Группы = nil
+			// cycles of ParentИд are cut
+			if visited[g.Ид] {
+				continue
+			}
+			visited[g.Ид] = true
+			if sub := build(g.Ид); len(sub) > 0 {
+				g.Группы = &sub
+			}
+			groups = append(groups, g)
+		}
+		return groups
+	}
+	return build("")
+}
+
+// Remove detaches group with its subgroups from tree
+func (k *Классификатор) Remove(id string) (Группа, bool) {
+	return removeGroup(&k.Группы, id)
+}
+
+func removeGroup(groups *[]Группа, id string) (Группа, bool) {
+	for idx, g := range *groups {
+		if g.Ид == id {
+			*groups = append((*groups)[:idx:idx], (*groups)[idx+1:]...)
+			return g, true
+		}
+		if g.Группы != nil {
+			if removed, found := removeGroup(g.Группы, id); found {
+				if len(*g.Группы) == 0 {
+					(*groups)[idx].Группы = nil
+				}
+				return removed, true
+			}
+		}
+	}
+	return Группа{}, false
+}
+
+// Move makes group with its subgroups the last child of parent,
+// top level one if parent is empty
+func (k *Классификатор) Move(id, parent string) error {
+	g := k.Find(id)
+	if g == nil {
+		return fmt.Errorf("move: unknown group %q", id)
+	}
+	if len(parent) > 0 {
+		if (&Классификатор{Группы: []Группа{*g}}).Find(parent) != nil {
+			return fmt.Errorf("move: group %q is %q or its subgroup", parent, id)
+		}
+		if k.Find(parent) == nil {
+			return fmt.Errorf("move: unknown group %q", parent)
+		}
+	}
+
+	moved, _ := k.Remove(id)
+	if len(parent) == 0 {
+		k.Группы = append(k.Группы, moved)
+		return nil
+	}
+	p := k.Find(parent)
+	if p.Группы == nil {
+		p.Группы = &[]Группа{}
+	}
+	*p.Группы = append(*p.Группы, moved)
+	return nil
+}
+
+// Prune keeps groups listed in keep and their ancestors
+func (k *Классификатор) Prune(keep map[string]bool) {
+	k.Группы = pruneGroups(k.Группы, keep)
+}
+
+func pruneGroups(groups []Группа, keep map[string]bool) []Группа {
+	var res []Группа
+	for _, g := range groups {
+		var sub *[]Группа
+		if g.Группы != nil {
+			if kept := pruneGroups(*g.Группы, keep); len(kept) > 0 {
+				sub = &kept
+			}
+		}
+		if keep[g.Ид] || sub != nil {
+			g.Группы = sub
+			res = append(res, g)
+		}
+	}
+	return res
+}
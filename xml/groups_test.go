@@ -0,0 +1,146 @@
+package xml
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// deep returns classifier of width top level groups each nested depth levels,
+// Ид of group is "g<top>.<level>"
+func deep(width, depth int) *Классификатор {
+	k := &Классификатор{}
+	for w := 0; w < width; w++ {
+		var sub *[]Группа
+		for d := depth - 1; d >= 0; d-- {
+			g := Группа{Ид: fmt.Sprintf("g%d.%d", w, d), Наименование: fmt.Sprintf("n%d.%d", w, d), Группы: sub}
+			if d == 0 {
+				k.Группы = append(k.Группы, g)
+			} else {
+				sub = &[]Группа{g}
+			}
+		}
+	}
+	return k
+}
+
+func TestWalk(t *testing.T) {
+	k := deep(2, 50)
+
+	var ids []string
+	assert.Nil(t, k.Walk(func(g *Группа, parents []*Группа) error {
+		ids = append(ids, g.Ид)
+		if g.Ид == "g0.49" {
+			assert.Equal(t, 49, len(parents))
+			assert.Equal(t, "g0.0", parents[0].Ид)
+			assert.Equal(t, "g0.48", parents[48].Ид)
+		}
+		return nil
+	}))
+	assert.Equal(t, 100, len(ids))
+	assert.Equal(t, "g0.0", ids[0])
+	assert.Equal(t, "g1.0", ids[50])
+
+	ids = nil
+	assert.Nil(t, k.Walk(func(g *Группа, parents []*Группа) error {
+		ids = append(ids, g.Ид)
+		if g.Ид == "g0.10" {
+			return SkipGroups
+		}
+		return nil
+	}))
+	assert.Equal(t, 61, len(ids))
+
+	stop := fmt.Errorf("stop")
+	assert.Equal(t, stop, k.Walk(func(g *Группа, parents []*Группа) error { return stop }))
+}
+
+func TestFindParent(t *testing.T) {
+	k := deep(3, 20)
+
+	g := k.Find("g2.19")
+	assert.Equal(t, "n2.19", g.Наименование)
+	g.Наименование = "renamed"
+	assert.Equal(t, "renamed", k.Find("g2.19").Наименование)
+	assert.Nil(t, k.Find("g3.0"))
+
+	p, found := k.Parent("g1.7")
+	assert.True(t, found)
+	assert.Equal(t, "g1.6", p.Ид)
+	p, found = k.Parent("g1.0")
+	assert.True(t, found)
+	assert.Nil(t, p)
+	_, found = k.Parent("g3.0")
+	assert.False(t, found)
+}
+
+func TestFlattenBuild(t *testing.T) {
+	k := deep(3, 30)
+
+	rows := k.Flatten()
+	assert.Equal(t, 90, len(rows))
+	assert.Equal(t, "", rows[0].ParentИд)
+	assert.Equal(t, "g0.28", rows[29].ParentИд)
+	assert.Equal(t, 30, len(rows[29].Path))
+	assert.Equal(t, "n0.29", rows[29].Path[29])
+	for _, row := range rows {
+		assert.Nil(t, row.Группы)
+	}
+
+	assert.Equal(t, k.Группы, BuildGroups(rows))
+
+	// unknown parents become top level, cycles are cut
+	built := BuildGroups([]GroupRow{
+		{Группа: Группа{Ид: "a"}, ParentИд: "unknown"},
+		{Группа: Группа{Ид: "b"}, ParentИд: "c"},
+		{Группа: Группа{Ид: "c"}, ParentИд: "b"},
+		{Группа: Группа{Ид: "d"}, ParentИд: "d"},
+	})
+	assert.Equal(t, []Группа{{Ид: "a"}, {Ид: "d"}}, built)
+	assert.Nil(t, BuildGroups(nil))
+}
+
+func TestMoveRemove(t *testing.T) {
+	k := deep(2, 10)
+
+	assert.Nil(t, k.Move("g1.5", "g0.9"))
+	p, _ := k.Parent("g1.5")
+	assert.Equal(t, "g0.9", p.Ид)
+	assert.Equal(t, 20, len(k.Flatten()))
+	assert.Nil(t, k.Find("g1.4").Группы)
+	path := k.Flatten()[10].Path
+	assert.Equal(t, []string{"n0.0", "n0.1", "n0.2", "n0.3", "n0.4", "n0.5", "n0.6", "n0.7", "n0.8", "n0.9", "n1.5"}, path)
+
+	assert.Nil(t, k.Move("g0.3", ""))
+	assert.Equal(t, 3, len(k.Группы))
+	assert.Equal(t, "g0.3", k.Группы[2].Ид)
+
+	assert.NotNil(t, k.Move("g0.3", "g1.5"))
+	assert.NotNil(t, k.Move("g0.3", "g0.3"))
+	assert.NotNil(t, k.Move("g0.3", "unknown"))
+	assert.NotNil(t, k.Move("unknown", ""))
+
+	removed, found := k.Remove("g0.3")
+	assert.True(t, found)
+	assert.Equal(t, "g0.3", removed.Ид)
+	assert.Equal(t, 8, len(k.Flatten()))
+	_, found = k.Remove("g0.3")
+	assert.False(t, found)
+}
+
+func TestPrune(t *testing.T) {
+	k := deep(3, 10)
+	orig := k.Flatten()
+
+	k.Prune(map[string]bool{"g1.4": true, "g2.0": true})
+	rows := k.Flatten()
+	assert.Equal(t, 6, len(rows))
+	assert.Equal(t, "g1.4", rows[4].Ид)
+	assert.Nil(t, k.Find("g1.4").Группы)
+	assert.Equal(t, "g2.0", rows[5].Ид)
+
+	k.Prune(nil)
+	assert.Nil(t, k.Группы)
+	assert.Equal(t, 30, len(orig))
+}
@@ -0,0 +1,45 @@
+package xml
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Images returns Картинка of all products as slash separated paths relative to dir
+// (directory of import.xml) in order of appearance without duplicates.
+// Names of files not found in dir are returned in missing
+func Images(x *КоммерческаяИнформация, dir string) (names []string, missing []string, err error) {
+	if x == nil || x.Каталог == nil {
+		return nil, nil, nil
+	}
+
+	seen := make(map[string]bool)
+	for _, p := range x.Каталог.Товары {
+		for _, img := range p.Картинка {
+			img = strings.TrimSpace(img)
+			if len(img) == 0 {
+				continue
+			}
+			name := path.Clean(strings.Replace(img, "\\", "/", -1))
+			if seen[name] {
+				continue
+			}
+			if path.IsAbs(name) || name == ".." || strings.HasPrefix(name, "../") {
+				return nil, nil, fmt.Errorf("image %q of product %s is outside of %s", img, p.Ид, dir)
+			}
+			seen[name] = true
+			names = append(names, name)
+
+			if _, err := os.Stat(filepath.Join(dir, filepath.FromSlash(name))); err != nil {
+				if !os.IsNotExist(err) {
+					return nil, nil, err
+				}
+				missing = append(missing, name)
+			}
+		}
+	}
+	return names, missing, nil
+}
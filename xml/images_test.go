@@ -0,0 +1,46 @@
+package xml
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImages(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cml")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	assert.Nil(t, os.MkdirAll(filepath.Join(dir, "import_files", "ab"), 0755))
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "import_files", "ab", "a.jpg"), []byte("a"), 0644))
+
+	x := &КоммерческаяИнформация{Каталог: &Каталог{Товары: []Товар{
+		{Ид: "p1", Картинка: []string{"import_files/ab/a.jpg", "import_files/ab/b.jpg"}},
+		{Ид: "p2", Картинка: []string{"import_files\\ab\\a.jpg"}},
+		{Ид: "p3", Картинка: []string{"", "  "}},
+	}}}
+
+	names, missing, err := Images(x, dir)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"import_files/ab/a.jpg", "import_files/ab/b.jpg"}, names)
+	assert.Equal(t, []string{"import_files/ab/b.jpg"}, missing)
+
+	x.Каталог.Товары[2].Картинка = []string{"../secret.jpg"}
+	_, _, err = Images(x, dir)
+	assert.NotNil(t, err)
+
+	names, missing, err = Images(&КоммерческаяИнформация{}, dir)
+	assert.Nil(t, err)
+	assert.Nil(t, names)
+	assert.Nil(t, missing)
+}
+
+func TestImagesExport(t *testing.T) {
+	names, missing, err := Images(ReadMust("testdata/import.xml"), "testdata")
+	assert.Nil(t, err)
+	assert.NotEqual(t, 0, len(names))
+	assert.Equal(t, names, missing)
+}
@@ -0,0 +1,288 @@
+package xml
+
+import (
+	"fmt"
+)
+
+// Merge applies delta onto base snapshot and returns the merged document.
+// Sections of delta with СодержитТолькоИзменения=false replace those of base,
+// otherwise groups, properties, products, price types, warehouses and offers are upserted by Ид
+// and products marked for deletion are removed. Классификатор has no flag of its own
+// and follows Каталог of delta, it is full without Каталог. Top level groups
+// of partial Классификатор keep their parents of base.
+// ИзменениеПакетаПредложений of delta replaces prices and stock of offers.
+// Base order is preserved, new elements are appended in order of delta.
+// Neither base nor delta are modified
+func Merge(base, delta *КоммерческаяИнформация) (*КоммерческаяИнформация, error) {
+	if base == nil {
+		base = new(КоммерческаяИнформация)
+	}
+	if delta == nil {
+		delta = new(КоммерческаяИнформация)
+	}
+
+	x := *base
+	if len(delta.ВерсияСхемы) > 0 {
+		x.ВерсияСхемы = delta.ВерсияСхемы
+	}
+	if len(delta.ДатаФормирования) > 0 {
+		x.ДатаФормирования = delta.ДатаФормирования
+	}
+
+	if delta.Классификатор != nil {
+		if base.Классификатор != nil && base.Классификатор.Ид != delta.Классификатор.Ид {
+			return nil, fmt.Errorf("merge: Классификатор Ид %q mismatch %q",
+				delta.Классификатор.Ид, base.Классификатор.Ид)
+		}
+		partial := delta.Каталог != nil && delta.Каталог.СодержитТолькоИзменения
+		x.Классификатор = mergeClassifier(base.Классификатор, delta.Классификатор, partial)
+	}
+
+	if delta.Каталог != nil {
+		if base.Каталог != nil && base.Каталог.Ид != delta.Каталог.Ид {
+			return nil, fmt.Errorf("merge: Каталог Ид %q mismatch %q",
+				delta.Каталог.Ид, base.Каталог.Ид)
+		}
+		x.Каталог = mergeCatalog(base.Каталог, delta.Каталог)
+	}
+
+	if delta.ПакетПредложений != nil {
+		if base.ПакетПредложений != nil && base.ПакетПредложений.Ид != delta.ПакетПредложений.Ид {
+			return nil, fmt.Errorf("merge: ПакетПредложений Ид %q mismatch %q",
+				delta.ПакетПредложений.Ид, base.ПакетПредложений.Ид)
+		}
+		x.ПакетПредложений = mergeOffers(base.ПакетПредложений, delta.ПакетПредложений)
+	}
+
+	if change := delta.ИзменениеПакетаПредложений; change != nil {
+		if x.ПакетПредложений != nil && x.ПакетПредложений.Ид != change.Ид {
+			return nil, fmt.Errorf("merge: ИзменениеПакетаПредложений Ид %q mismatch %q",
+				change.Ид, x.ПакетПредложений.Ид)
+		}
+		x.ПакетПредложений = applyChanges(x.ПакетПредложений, change)
+	}
+
+	return &x, nil
+}
+
+func mergeClassifier(base, delta *Классификатор, partial bool) *Классификатор {
+	if base == nil || !partial {
+		x := *delta
+		return &x
+	}
+
+	rows := base.Flatten()
+	index := make(map[string]int, len(rows))
+	for idx, row := range rows {
+		index[row.Ид] = idx
+	}
+
+	for _, row := range delta.Flatten() {
+		if idx, found := index[row.Ид]; found {
+			// delta does not tell parent of its top level groups
+			if len(row.ParentИд) == 0 {
+				row.ParentИд = rows[idx].ParentИд
+			}
+			rows[idx] = row
+		} else {
+			index[row.Ид] = len(rows)
+			rows = append(rows, row)
+		}
+	}
+
+	props := append([]Свойство(nil), base.Properties()...)
+	propIdx := make(map[string]int, len(props))
+	for idx, p := range props {
+		propIdx[p.Ид] = idx
+	}
+	for _, p := range delta.Properties() {
+		if idx, found := propIdx[p.Ид]; found {
+			props[idx] = p
+		} else {
+			propIdx[p.Ид] = len(props)
+			props = append(props, p)
+		}
+	}
+
+	x := *delta
+	x.Группы = BuildGroups(rows)
+	x.Свойства = nil
+	if len(props) > 0 {
+		x.Свойства = &props
+	}
+	return &x
+}
+
+func mergeCatalog(base, delta *Каталог) *Каталог {
+	if base == nil || !delta.СодержитТолькоИзменения {
+		x := *delta
+		x.Товары = append([]Товар(nil), delta.Товары...)
+		return &x
+	}
+
+	x := *delta
+	x.СодержитТолькоИзменения = base.СодержитТолькоИзменения
+	x.Товары = append([]Товар(nil), base.Товары...)
+
+	index := make(map[string]int)
+	for idx, p := range x.Товары {
+		index[p.Ид] = idx
+	}
+	deleted := make(map[string]bool)
+	for _, p := range delta.Товары {
+		if p.Deleted() {
+			deleted[p.Ид] = true
+			continue
+		}
+		if idx, found := index[p.Ид]; found {
+			x.Товары[idx] = p
+			continue
+		}
+		index[p.Ид] = len(x.Товары)
+		x.Товары = append(x.Товары, p)
+	}
+
+	if len(deleted) > 0 {
+		products := x.Товары[:0]
+		for _, p := range x.Товары {
+			if !deleted[p.Ид] {
+				products = append(products, p)
+			}
+		}
+		x.Товары = products
+	}
+	return &x
+}
+
+func mergeOffers(base, delta *ПакетПредложений) *ПакетПредложений {
+	if base == nil || !delta.СодержитТолькоИзменения {
+		x := *delta
+		x.Предложения = append([]Предложение(nil), delta.Предложения...)
+		return &x
+	}
+
+	x := *delta
+	x.СодержитТолькоИзменения = base.СодержитТолькоИзменения
+
+	x.ТипыЦен = append([]ТипЦены(nil), base.ТипыЦен...)
+	for _, t := range delta.ТипыЦен {
+		found := false
+		for idx := range x.ТипыЦен {
+			if x.ТипыЦен[idx].Ид == t.Ид {
+				x.ТипыЦен[idx], found = t, true
+				break
+			}
+		}
+		if !found {
+			x.ТипыЦен = append(x.ТипыЦен, t)
+		}
+	}
+
+	x.Склады = append([]Склад(nil), base.Склады...)
+	for _, s := range delta.Склады {
+		found := false
+		for idx := range x.Склады {
+			if x.Склады[idx].Ид == s.Ид {
+				x.Склады[idx], found = s, true
+				break
+			}
+		}
+		if !found {
+			x.Склады = append(x.Склады, s)
+		}
+	}
+
+	x.Предложения = append([]Предложение(nil), base.Предложения...)
+	index := make(map[string]int)
+	for idx, o := range x.Предложения {
+		index[o.Ид] = idx
+	}
+	for _, o := range delta.Предложения {
+		if idx, found := index[o.Ид]; found {
+			x.Предложения[idx] = mergeOffer(x.Предложения[idx], o)
+			continue
+		}
+		index[o.Ид] = len(x.Предложения)
+		x.Предложения = append(x.Предложения, o)
+	}
+	return &x
+}
+
+// mergeOffer updates filled fields of base, prices by ИдТипаЦены and stock by ИдСклада
+func mergeOffer(base, delta Предложение) Предложение {
+	x := base
+	if len(delta.Артикул) > 0 {
+		x.Артикул = delta.Артикул
+	}
+	if len(delta.Наименование) > 0 {
+		x.Наименование = delta.Наименование
+	}
+	if len(delta.БазоваяЕдиница.БазоваяЕдиница) > 0 {
+		x.БазоваяЕдиница = delta.БазоваяЕдиница
+	}
+	if delta.ХарактеристикиТовара != nil {
+		x.ХарактеристикиТовара = delta.ХарактеристикиТовара
+	}
+	if len(delta.Количество) > 0 {
+		x.Количество = delta.Количество
+	}
+
+	x.Цены = append([]Цена(nil), base.Цены...)
+	for _, p := range delta.Цены {
+		found := false
+		for idx := range x.Цены {
+			if x.Цены[idx].ИдТипаЦены == p.ИдТипаЦены {
+				x.Цены[idx], found = p, true
+				break
+			}
+		}
+		if !found {
+			x.Цены = append(x.Цены, p)
+		}
+	}
+
+	x.Склад = append([]Остаток(nil), base.Склад...)
+	for _, s := range delta.Склад {
+		found := false
+		for idx := range x.Склад {
+			if x.Склад[idx].ИдСклада == s.ИдСклада {
+				x.Склад[idx], found = s, true
+				break
+			}
+		}
+		if !found {
+			x.Склад = append(x.Склад, s)
+		}
+	}
+	return x
+}
+
+// applyChanges sets prices and stock of offers, unknown offers are appended
+func applyChanges(base *ПакетПредложений, change *ИзменениеПакетаПредложений) *ПакетПредложений {
+	x := &ПакетПредложений{
+		Ид:               change.Ид,
+		Наименование:     change.Наименование,
+		ИдКаталога:       change.ИдКаталога,
+		ИдКлассификатора: change.ИдКлассификатора,
+	}
+	if base != nil {
+		x = new(ПакетПредложений)
+		*x = *base
+	}
+	x.Предложения = append([]Предложение(nil), x.Предложения...)
+
+	index := make(map[string]int, len(x.Предложения))
+	for idx, o := range x.Предложения {
+		index[o.Ид] = idx
+	}
+	for idx := range change.Предложения {
+		c := &change.Предложения[idx]
+		if i, found := index[c.Ид]; found {
+			c.Apply(&x.Предложения[i])
+		} else {
+			index[c.Ид] = len(x.Предложения)
+			x.Предложения = append(x.Предложения, c.Offer())
+		}
+	}
+	return x
+}
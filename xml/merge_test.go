@@ -0,0 +1,199 @@
+package xml
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergeDiffRoundTrip(t *testing.T) {
+	prev, next := snapshot(), snapshot()
+
+	(*next.Классификатор.Группы[0].Группы)[1].Наименование = "Для женщин"
+	next.Классификатор.Группы = append(next.Классификатор.Группы, Группа{Ид: "g3", Наименование: "Сумки"})
+	next.Каталог.Товары[0].Наименование = "Брюки мужские"
+	next.Каталог.Товары = append(next.Каталог.Товары[:2], Товар{Ид: "p4", Наименование: "Сумка"})
+	next.ПакетПредложений.Предложения[1].Цены[0].ЦенаЗаЕдиницу = "250"
+
+	delta, _ := Diff(prev, next)
+	merged, err := Merge(prev, delta)
+	assert.Nil(t, err)
+	assert.Equal(t, next, merged)
+
+	// deterministic output
+	var a, b bytes.Buffer
+	assert.Nil(t, Write(merged, &a))
+	merged, _ = Merge(prev, delta)
+	assert.Nil(t, Write(merged, &b))
+	assert.Equal(t, a.String(), b.String())
+}
+
+func TestMergeMoveGroup(t *testing.T) {
+	delta := &КоммерческаяИнформация{
+		Классификатор: &Классификатор{
+			Ид: "cls",
+			Группы: []Группа{
+				{Ид: "g2", Наименование: "Обувь", Группы: &[]Группа{
+					{Ид: "g11", Наименование: "Мужская обувь"},
+				}},
+			},
+		},
+		Каталог: &Каталог{Ид: "cat", СодержитТолькоИзменения: true},
+	}
+
+	x, err := Merge(snapshot(), delta)
+	assert.Nil(t, err)
+
+	groups := x.Классификатор.Группы
+	assert.Equal(t, 2, len(groups))
+	assert.Equal(t, []Группа{{Ид: "g12", Наименование: "Женская"}}, *groups[0].Группы)
+	assert.Equal(t, []Группа{{Ид: "g11", Наименование: "Мужская обувь"}}, *groups[1].Группы)
+
+	// top level group of delta stays under its parent
+	delta.Классификатор.Группы = []Группа{{Ид: "g12", Наименование: "Для женщин"}}
+	x, err = Merge(snapshot(), delta)
+	assert.Nil(t, err)
+	groups = x.Классификатор.Группы
+	assert.Equal(t, 2, len(groups))
+	assert.Equal(t, []Группа{
+		{Ид: "g11", Наименование: "Мужская"},
+		{Ид: "g12", Наименование: "Для женщин"},
+	}, *groups[0].Группы)
+}
+
+func TestMergeProperties(t *testing.T) {
+	base := snapshot()
+	base.Классификатор.Свойства = &[]Свойство{
+		{Ид: "color", Наименование: "Цвет"},
+		{Ид: "size", Наименование: "Размер"},
+	}
+	delta := &КоммерческаяИнформация{
+		Классификатор: &Классификатор{Ид: "cls", Свойства: &[]Свойство{
+			{Ид: "size", Наименование: "Размер одежды"},
+			{Ид: "brand", Наименование: "Бренд"},
+		}},
+		Каталог: &Каталог{Ид: "cat", СодержитТолькоИзменения: true},
+	}
+
+	x, err := Merge(base, delta)
+	assert.Nil(t, err)
+	assert.Equal(t, []Свойство{
+		{Ид: "color", Наименование: "Цвет"},
+		{Ид: "size", Наименование: "Размер одежды"},
+		{Ид: "brand", Наименование: "Бренд"},
+	}, x.Классификатор.Properties())
+	assert.Equal(t, "Размер", base.Классификатор.Properties()[1].Наименование)
+
+	// delta without properties keeps them
+	delta.Классификатор.Свойства = nil
+	x, err = Merge(base, delta)
+	assert.Nil(t, err)
+	assert.Equal(t, base.Классификатор.Properties(), x.Классификатор.Properties())
+}
+
+func TestMergeFullClassifier(t *testing.T) {
+	groups := []Группа{{Ид: "g3", Наименование: "Сумки"}}
+	delta := &КоммерческаяИнформация{Классификатор: &Классификатор{Ид: "cls", Группы: groups}}
+
+	// without Каталог
+	x, err := Merge(snapshot(), delta)
+	assert.Nil(t, err)
+	assert.Equal(t, groups, x.Классификатор.Группы)
+
+	delta.Каталог = &Каталог{Ид: "cat"}
+	x, err = Merge(snapshot(), delta)
+	assert.Nil(t, err)
+	assert.Equal(t, groups, x.Классификатор.Группы)
+}
+
+func TestMergeOffers(t *testing.T) {
+	delta := &КоммерческаяИнформация{
+		ПакетПредложений: &ПакетПредложений{
+			СодержитТолькоИзменения: true,
+			Ид:                      "pack",
+			ТипыЦен:                 []ТипЦены{{Ид: "wholesale", Наименование: "Оптовая"}},
+			Склады:                  []Склад{{Ид: "w1", Наименование: "Основной"}},
+			Предложения: []Предложение{
+				{Ид: "p1",
+					Цены:  []Цена{{ИдТипаЦены: "wholesale", ЦенаЗаЕдиницу: "90"}},
+					Склад: []Остаток{{ИдСклада: "w1", КоличествоНаСкладе: "1"}}},
+				{Ид: "p2", Количество: "0",
+					Цены: []Цена{{ИдТипаЦены: "retail", ЦенаЗаЕдиницу: "210"}}},
+			},
+		},
+	}
+
+	base := snapshot()
+	x, err := Merge(base, delta)
+	assert.Nil(t, err)
+
+	pack := x.ПакетПредложений
+	assert.False(t, pack.СодержитТолькоИзменения)
+	assert.Equal(t, 2, len(pack.ТипыЦен))
+	assert.Equal(t, 1, len(pack.Склады))
+	assert.Equal(t, 3, len(pack.Предложения))
+
+	assert.Equal(t, "1", pack.Предложения[0].Количество)
+	assert.Equal(t, []Цена{
+		{ИдТипаЦены: "retail", ЦенаЗаЕдиницу: "100"},
+		{ИдТипаЦены: "wholesale", ЦенаЗаЕдиницу: "90"},
+	}, pack.Предложения[0].Цены)
+	assert.Equal(t, 1, len(pack.Предложения[0].Склад))
+
+	assert.Equal(t, "0", pack.Предложения[1].Количество)
+	assert.Equal(t, "210", pack.Предложения[1].Цены[0].ЦенаЗаЕдиницу)
+
+	// base is not modified
+	assert.Equal(t, 1, len(base.ПакетПредложений.Предложения[0].Цены))
+	assert.Equal(t, "200", base.ПакетПредложений.Предложения[1].Цены[0].ЦенаЗаЕдиницу)
+}
+
+func TestMergeFullReplace(t *testing.T) {
+	delta := &КоммерческаяИнформация{
+		Каталог: &Каталог{Ид: "cat", Товары: []Товар{{Ид: "p9"}}},
+	}
+
+	x, err := Merge(snapshot(), delta)
+	assert.Nil(t, err)
+	assert.Equal(t, []Товар{{Ид: "p9"}}, x.Каталог.Товары)
+
+	delta.Каталог.Ид = "other"
+	_, err = Merge(snapshot(), delta)
+	assert.NotNil(t, err)
+}
+
+func TestMergeChanges(t *testing.T) {
+	base := ReadMust("testdata/offers.xml")
+	x, err := Merge(base, ReadMust("testdata/prices.xml"))
+	assert.Nil(t, err)
+	x, err = Merge(x, ReadMust("testdata/rests.xml"))
+	assert.Nil(t, err)
+
+	pack := x.ПакетПредложений
+	assert.Equal(t, len(base.ПакетПредложений.Предложения), len(pack.Предложения))
+	var o Предложение
+	for _, offer := range pack.Предложения {
+		if offer.Ид == "c5e4a1b1-3d2e-11e9-80d4-0cc47a7c2f11" {
+			o = offer
+		}
+	}
+	assert.Equal(t, 2, len(o.Цены))
+	assert.Equal(t, "3300", o.Цены[0].ЦенаЗаЕдиницу)
+	assert.Equal(t, "7", o.Количество)
+	assert.Equal(t, []Остаток{
+		{ИдСклада: "e2f1b3c4-5d6e-11e9-80d4-0cc47a7c2f11", КоличествоНаСкладе: "4"},
+		{ИдСклада: "e2f1b3c5-5d6e-11e9-80d4-0cc47a7c2f11", КоличествоНаСкладе: "3"},
+	}, o.Склад)
+	assert.Equal(t, base.ПакетПредложений, ReadMust("testdata/offers.xml").ПакетПредложений)
+
+	// unknown offers are appended
+	x, err = Merge(nil, ReadMust("testdata/rests.xml"))
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(x.ПакетПредложений.Предложения))
+	assert.Equal(t, "0", x.ПакетПредложений.Предложения[1].Количество)
+
+	_, err = Merge(&КоммерческаяИнформация{ПакетПредложений: &ПакетПредложений{Ид: "other"}},
+		ReadMust("testdata/rests.xml"))
+	assert.NotNil(t, err)
+}
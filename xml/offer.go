@@ -0,0 +1,81 @@
+package xml
+
+import (
+	"strings"
+)
+
+// SplitID splits Ид of offer "productId#variantId" into Ид of product and Ид of variant.
+// Variant is empty for offers of products without characteristics
+func SplitID(id string) (string, string) {
+	if idx := strings.IndexByte(id, '#'); idx >= 0 {
+		return id[:idx], id[idx+1:]
+	}
+	return id, ""
+}
+
+// ProductID returns Ид of offered product
+func (o *Предложение) ProductID() string {
+	id, _ := SplitID(o.Ид)
+	return id
+}
+
+// VariantID returns Ид of product characteristic or empty string
+func (o *Предложение) VariantID() string {
+	_, id := SplitID(o.Ид)
+	return id
+}
+
+// Characteristics returns ХарактеристикиТовара as "Наименование: Значение, ..."
+func (o *Предложение) Characteristics() string {
+	if o.ХарактеристикиТовара == nil {
+		return ""
+	}
+	values := make([]string, len(*o.ХарактеристикиТовара))
+	for idx, c := range *o.ХарактеристикиТовара {
+		values[idx] = c.Наименование + ": " + c.Значение
+	}
+	return strings.Join(values, ", ")
+}
+
+// Prices returns Цены of change or nil
+func (c *ИзменениеПредложения) Prices() []Цена {
+	if c.Цены == nil {
+		return nil
+	}
+	return *c.Цены
+}
+
+// Rests returns Остатки of change or nil
+func (c *ИзменениеПредложения) Rests() []ОстатокТовара {
+	if c.Остатки == nil {
+		return nil
+	}
+	return *c.Остатки
+}
+
+// Offer returns Предложение holding prices and stock of change only
+func (c *ИзменениеПредложения) Offer() Предложение {
+	o := Предложение{Ид: c.Ид}
+	c.Apply(&o)
+	return o
+}
+
+// Apply sets prices and stock of o from change.
+// Цены and Остатки present in change replace those of o,
+// Количество is dropped by Остатки without total
+func (c *ИзменениеПредложения) Apply(o *Предложение) {
+	if prices := c.Prices(); len(prices) > 0 {
+		o.Цены = append([]Цена(nil), prices...)
+	}
+	rests := c.Rests()
+	if len(rests) > 0 {
+		o.Склад, o.Количество = nil, ""
+	}
+	for _, r := range rests {
+		if r.Склад == nil {
+			o.Количество = r.Количество
+			continue
+		}
+		o.Склад = append(o.Склад, Остаток{ИдСклада: r.Склад.Ид, КоличествоНаСкладе: r.Склад.Количество})
+	}
+}
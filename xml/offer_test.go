@@ -0,0 +1,82 @@
+package xml
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOfferID(t *testing.T) {
+	o := &Предложение{Ид: "bd72d8f9-55bc-11d9-848a-00112f43529a#bd72d8fa-55bc-11d9-848a-00112f43529a"}
+	assert.Equal(t, "bd72d8f9-55bc-11d9-848a-00112f43529a", o.ProductID())
+	assert.Equal(t, "bd72d8fa-55bc-11d9-848a-00112f43529a", o.VariantID())
+
+	o = &Предложение{Ид: "bd72d8f9-55bc-11d9-848a-00112f43529a"}
+	assert.Equal(t, o.Ид, o.ProductID())
+	assert.Equal(t, "", o.VariantID())
+}
+
+func TestOfferCharacteristics(t *testing.T) {
+	o := &Предложение{ХарактеристикиТовара: &[]ХарактеристикаТовара{
+		{Наименование: "Размер", Значение: "42"},
+		{Наименование: "Цвет", Значение: "Красный"},
+	}}
+	assert.Equal(t, "Размер: 42, Цвет: Красный", o.Characteristics())
+	assert.Equal(t, "", (&Предложение{}).Characteristics())
+
+	// offers without characteristics are written without them
+	var buf bytes.Buffer
+	assert.Nil(t, Write(&КоммерческаяИнформация{ПакетПредложений: &ПакетПредложений{
+		Предложения: []Предложение{{Ид: "p1"}}}}, &buf))
+	assert.NotContains(t, buf.String(), "ХарактеристикиТовара")
+}
+
+func TestOfferChange(t *testing.T) {
+	o := Предложение{Ид: "p1", Наименование: "Брюки", Количество: "1",
+		Цены:  []Цена{{ИдТипаЦены: "retail", ЦенаЗаЕдиницу: "100"}},
+		Склад: []Остаток{{ИдСклада: "w1", КоличествоНаСкладе: "1"}}}
+
+	prices := &ИзменениеПредложения{Ид: "p1", Цены: &[]Цена{
+		{ИдТипаЦены: "retail", ЦенаЗаЕдиницу: "120"}, {ИдТипаЦены: "opt", ЦенаЗаЕдиницу: "90"}}}
+	prices.Apply(&o)
+	assert.Equal(t, 2, len(o.Цены))
+	assert.Equal(t, "120", o.Цены[0].ЦенаЗаЕдиницу)
+	assert.Equal(t, "1", o.Количество)
+	assert.Equal(t, 1, len(o.Склад))
+
+	rests := &ИзменениеПредложения{Ид: "p1", Остатки: &[]ОстатокТовара{
+		{Количество: "5"},
+		{Склад: &ОстатокНаСкладе{Ид: "w2", Количество: "5"}},
+	}}
+	rests.Apply(&o)
+	assert.Equal(t, "5", o.Количество)
+	assert.Equal(t, []Остаток{{ИдСклада: "w2", КоличествоНаСкладе: "5"}}, o.Склад)
+	assert.Equal(t, 2, len(o.Цены))
+
+	assert.Equal(t, Предложение{Ид: "p1", Количество: "5",
+		Склад: []Остаток{{ИдСклада: "w2", КоличествоНаСкладе: "5"}}}, rests.Offer())
+
+	// stale total is not kept by stock on warehouses
+	stock := &ИзменениеПредложения{Ид: "p1", Остатки: &[]ОстатокТовара{
+		{Склад: &ОстатокНаСкладе{Ид: "w1", Количество: "3"}},
+	}}
+	stock.Apply(&o)
+	assert.Equal(t, "", o.Количество)
+	assert.Equal(t, []Остаток{{ИдСклада: "w1", КоличествоНаСкладе: "3"}}, o.Склад)
+
+	// change does not share slices with offer
+	prices.Apply(&o)
+	o.Цены[0].ЦенаЗаЕдиницу = "0"
+	assert.Equal(t, "120", prices.Prices()[0].ЦенаЗаЕдиницу)
+}
+
+func TestOfferChangeWrite(t *testing.T) {
+	// prices do not clear stock and vice versa
+	var buf bytes.Buffer
+	assert.Nil(t, Write(ReadMust("testdata/prices.xml"), &buf))
+	assert.NotContains(t, buf.String(), "Остатки")
+	buf.Reset()
+	assert.Nil(t, Write(ReadMust("testdata/rests.xml"), &buf))
+	assert.NotContains(t, buf.String(), "Цены")
+}
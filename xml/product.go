@@ -0,0 +1,64 @@
+package xml
+
+// СтатусУдален of deleted Товар
+const СтатусУдален = "Удален"
+
+// Deleted reports whether product is marked for deletion
+func (p *Товар) Deleted() bool {
+	return p.ПометкаУдаления || p.Статус == СтатусУдален
+}
+
+// Requisite returns value of ЗначениеРеквизита by Наименование
+func (p *Товар) Requisite(name string) (string, bool) {
+	for _, r := range p.ЗначенияРеквизитов {
+		if r.Наименование == name {
+			return r.Значение, true
+		}
+	}
+	return "", false
+}
+
+// Tax returns Ставка of СтавкаНалога by Наименование (НДС)
+func (p *Товар) Tax(name string) (string, bool) {
+	if p.СтавкиНалогов == nil {
+		return "", false
+	}
+	for _, t := range *p.СтавкиНалогов {
+		if t.Наименование == name {
+			return t.Ставка, true
+		}
+	}
+	return "", false
+}
+
+// Property returns Значение of ЗначенияСвойства by Ид of Свойство
+func (p *Товар) Property(id string) (string, bool) {
+	if p.ЗначенияСвойств == nil {
+		return "", false
+	}
+	for _, v := range *p.ЗначенияСвойств {
+		if v.Ид == id {
+			return v.Значение, true
+		}
+	}
+	return "", false
+}
+
+// Properties returns Свойства of classifier or nil
+func (k *Классификатор) Properties() []Свойство {
+	if k.Свойства == nil {
+		return nil
+	}
+	return *k.Свойства
+}
+
+// Value returns Значение of ВариантЗначения for Справочник properties
+// or value itself for the others
+func (s *Свойство) Value(value string) string {
+	for _, v := range s.ВариантыЗначений {
+		if v.ИдЗначения == value {
+			return v.Значение
+		}
+	}
+	return value
+}
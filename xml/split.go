@@ -0,0 +1,223 @@
+package xml
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// SplitLimits of every part, zero means no limit
+type SplitLimits struct {
+	// Products is the number of Товар or Предложение per part
+	Products int
+	// Bytes is the size of part written by Write.
+	// Part holds one element at least, so it may be exceeded by huge element
+	Bytes int
+}
+
+// Split partitions Каталог.Товары, ПакетПредложений.Предложения and
+// ИзменениеПакетаПредложений.Предложения of x into documents respecting limits. Классификатор goes to the first part only,
+// ТипыЦен and Склады are repeated in every part.
+// Parts after the first one have СодержитТолькоИзменения set, so applying
+// parts in order by Merge yields x
+func Split(x *КоммерческаяИнформация, limits SplitLimits) ([]*КоммерческаяИнформация, error) {
+	var parts []*КоммерческаяИнформация
+
+	var (
+		current *КоммерческаяИнформация
+		size    int
+		count   int
+		first   bool // current part is the first one of section
+	)
+
+	// part returns skeleton of the next document
+	part := func() *КоммерческаяИнформация {
+		p := *x
+		p.Документ = nil
+		if len(parts) > 0 {
+			p.Классификатор = nil
+		}
+		if x.Каталог != nil {
+			c := *x.Каталог
+			c.Товары = nil
+			c.СодержитТолькоИзменения = c.СодержитТолькоИзменения || !first
+			p.Каталог = &c
+		}
+		if x.ПакетПредложений != nil {
+			o := *x.ПакетПредложений
+			o.Предложения = nil
+			o.СодержитТолькоИзменения = o.СодержитТолькоИзменения || !first
+			p.ПакетПредложений = &o
+		}
+		if x.ИзменениеПакетаПредложений != nil {
+			c := *x.ИзменениеПакетаПредложений
+			c.Предложения = nil
+			p.ИзменениеПакетаПредложений = &c
+		}
+		return &p
+	}
+
+	// next starts new part if current one is full
+	next := func(n int) error {
+		if current != nil && (limits.Products <= 0 || count < limits.Products) &&
+			(limits.Bytes <= 0 || size+n <= limits.Bytes) {
+			return nil
+		}
+		first = current == nil
+		current = part()
+		parts = append(parts, current)
+		count = 0
+		size = 0
+		if limits.Bytes > 0 {
+			var buf bytes.Buffer
+			if err := Write(current, &buf); err != nil {
+				return err
+			}
+			size = buf.Len()
+		}
+		return nil
+	}
+
+	// element size as written by Write at depth of Товары>Товар
+	sizeOf := func(v interface{}) (int, error) {
+		if limits.Bytes <= 0 {
+			return 0, nil
+		}
+		buf, err := xml.MarshalIndent(v, "      ", "  ")
+		if err != nil {
+			return 0, err
+		}
+		return len(buf) + 1, nil
+	}
+
+	if x.Каталог != nil {
+		for _, p := range x.Каталог.Товары {
+			n, err := sizeOf(p)
+			if err != nil {
+				return nil, err
+			}
+			if err := next(n); err != nil {
+				return nil, err
+			}
+			current.Каталог.Товары = append(current.Каталог.Товары, p)
+			size += n
+			count++
+		}
+	}
+
+	// offers of document holding both sections start new part
+	current = nil
+	if x.ПакетПредложений != nil {
+		for _, o := range x.ПакетПредложений.Предложения {
+			n, err := sizeOf(o)
+			if err != nil {
+				return nil, err
+			}
+			if err := next(n); err != nil {
+				return nil, err
+			}
+			if current.Каталог != nil && len(current.Каталог.Товары) == 0 {
+				current.Каталог = nil
+			}
+			current.ПакетПредложений.Предложения = append(current.ПакетПредложений.Предложения, o)
+			size += n
+			count++
+		}
+	}
+
+	// prices and rests of 3.x start new part as well
+	current = nil
+	if x.ИзменениеПакетаПредложений != nil {
+		for _, o := range x.ИзменениеПакетаПредложений.Предложения {
+			n, err := sizeOf(o)
+			if err != nil {
+				return nil, err
+			}
+			if err := next(n); err != nil {
+				return nil, err
+			}
+			if current.Каталог != nil && len(current.Каталог.Товары) == 0 {
+				current.Каталог = nil
+			}
+			change := current.ИзменениеПакетаПредложений
+			change.Предложения = append(change.Предложения, o)
+			size += n
+			count++
+		}
+	}
+
+	for _, p := range parts {
+		if p.ПакетПредложений != nil && len(p.ПакетПредложений.Предложения) == 0 {
+			p.ПакетПредложений = nil
+		}
+		if p.ИзменениеПакетаПредложений != nil && len(p.ИзменениеПакетаПредложений.Предложения) == 0 {
+			p.ИзменениеПакетаПредложений = nil
+		}
+	}
+	if len(parts) == 0 {
+		first = true
+		parts = append(parts, part())
+	}
+	if len(x.Документ) > 0 {
+		parts[0].Документ = x.Документ
+	}
+	return parts, nil
+}
+
+// PartName returns file name of idx-th part expected by Bitrix importer:
+// import0_1.xml, import1_1.xml... or offers0_1.xml... for documents
+// without Классификатор and Каталог, prices0_1.xml or rests0_1.xml
+// for ИзменениеПакетаПредложений
+func PartName(x *КоммерческаяИнформация, idx int) string {
+	return fmt.Sprintf("%s%d_1.xml", partKind(x), idx)
+}
+
+func partKind(x *КоммерческаяИнформация) string {
+	if x.Классификатор != nil || x.Каталог != nil {
+		return "import"
+	}
+	if x.ПакетПредложений != nil {
+		return "offers"
+	}
+	if change := x.ИзменениеПакетаПредложений; change != nil {
+		for idx := range change.Предложения {
+			if len(change.Предложения[idx].Prices()) > 0 {
+				return "prices"
+			}
+		}
+		if len(change.Предложения) > 0 {
+			return "rests"
+		}
+	}
+	return "import"
+}
+
+// WriteSplit splits x and writes parts to dir, returns names of files
+func WriteSplit(x *КоммерческаяИнформация, limits SplitLimits, dir string) ([]string, error) {
+	parts, err := Split(x, limits)
+	if err != nil {
+		return nil, err
+	}
+	counts := make(map[string]int)
+	names := make([]string, len(parts))
+	for idx, p := range parts {
+		kind := partKind(p)
+		names[idx] = PartName(p, counts[kind])
+		counts[kind]++
+
+		f, err := os.Create(filepath.Join(dir, names[idx]))
+		if err != nil {
+			return nil, err
+		}
+		err = Write(p, f)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return names, nil
+}
@@ -0,0 +1,138 @@
+package xml
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// large returns document of n products and n offers
+func large(n int) *КоммерческаяИнформация {
+	x := snapshot()
+	x.Каталог.Товары = nil
+	x.ПакетПредложений.Предложения = nil
+	for i := 0; i < n; i++ {
+		id := fmt.Sprintf("p%03d", i)
+		x.Каталог.Товары = append(x.Каталог.Товары, Товар{Ид: id, Наименование: "Товар " + id,
+			Группы: []string{"g1"}})
+		x.ПакетПредложений.Предложения = append(x.ПакетПредложений.Предложения, Предложение{Ид: id,
+			Цены: []Цена{{ИдТипаЦены: "retail", ЦенаЗаЕдиницу: "100"}}})
+	}
+	return x
+}
+
+func TestSplitProducts(t *testing.T) {
+	x := large(25)
+	parts, err := Split(x, SplitLimits{Products: 10})
+	assert.Nil(t, err)
+	assert.Equal(t, 6, len(parts))
+
+	assert.NotNil(t, parts[0].Классификатор)
+	assert.Equal(t, 10, len(parts[0].Каталог.Товары))
+	assert.Nil(t, parts[0].ПакетПредложений)
+	assert.False(t, parts[0].Каталог.СодержитТолькоИзменения)
+	assert.Nil(t, parts[1].Классификатор)
+	assert.True(t, parts[1].Каталог.СодержитТолькоИзменения)
+	assert.Equal(t, 5, len(parts[2].Каталог.Товары))
+
+	assert.Nil(t, parts[3].Каталог)
+	assert.Nil(t, parts[3].Классификатор)
+	assert.Equal(t, 10, len(parts[3].ПакетПредложений.Предложения))
+	assert.Equal(t, x.ПакетПредложений.ТипыЦен, parts[5].ПакетПредложений.ТипыЦен)
+
+	// parts applied in order make the original document
+	var merged *КоммерческаяИнформация
+	for _, p := range parts {
+		merged, err = Merge(merged, p)
+		assert.Nil(t, err)
+	}
+	assert.Equal(t, x, merged)
+}
+
+func TestSplitBytes(t *testing.T) {
+	x := large(100)
+	x.ПакетПредложений = nil
+
+	var whole bytes.Buffer
+	assert.Nil(t, Write(x, &whole))
+	limit := whole.Len() / 4
+
+	parts, err := Split(x, SplitLimits{Bytes: limit})
+	assert.Nil(t, err)
+	assert.True(t, len(parts) >= 4)
+	total := 0
+	for _, p := range parts {
+		var buf bytes.Buffer
+		assert.Nil(t, Write(p, &buf))
+		assert.True(t, buf.Len() <= limit, "%d > %d", buf.Len(), limit)
+		total += len(p.Каталог.Товары)
+	}
+	assert.Equal(t, 100, total)
+
+	// element larger than limit makes its own part
+	parts, err = Split(x, SplitLimits{Bytes: 1})
+	assert.Nil(t, err)
+	assert.Equal(t, 100, len(parts))
+}
+
+func TestSplitNoLimits(t *testing.T) {
+	x := snapshot()
+	parts, err := Split(x, SplitLimits{})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(parts))
+	assert.Equal(t, x.Каталог, parts[0].Каталог)
+
+	parts, err = Split(&КоммерческаяИнформация{ВерсияСхемы: "2.05"}, SplitLimits{Products: 1})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(parts))
+}
+
+func TestWriteSplit(t *testing.T) {
+	dir, err := ioutil.TempDir("", "split")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	names, err := WriteSplit(large(5), SplitLimits{Products: 2}, dir)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"import0_1.xml", "import1_1.xml", "import2_1.xml",
+		"offers0_1.xml", "offers1_1.xml", "offers2_1.xml"}, names)
+
+	x, err := ReadFile(filepath.Join(dir, "offers2_1.xml"))
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(x.ПакетПредложений.Предложения))
+
+	names, err = WriteSplit(ReadMust("testdata/prices.xml"), SplitLimits{}, dir)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"prices0_1.xml"}, names)
+	names, err = WriteSplit(ReadMust("testdata/rests.xml"), SplitLimits{Products: 1}, dir)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"rests0_1.xml", "rests1_1.xml"}, names)
+}
+
+func TestSplitChanges(t *testing.T) {
+	x := ReadMust("testdata/rests.xml")
+	parts, err := Split(x, SplitLimits{Products: 1})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(parts))
+	for idx, p := range parts {
+		assert.Nil(t, p.Каталог)
+		assert.Nil(t, p.ПакетПредложений)
+		assert.Equal(t, x.ИзменениеПакетаПредложений.Ид, p.ИзменениеПакетаПредложений.Ид)
+		assert.Equal(t, x.ИзменениеПакетаПредложений.Предложения[idx:idx+1], p.ИзменениеПакетаПредложений.Предложения)
+	}
+
+	// changes follow offers in parts of their own
+	offers := ReadMust("testdata/offers.xml")
+	offers.ИзменениеПакетаПредложений = x.ИзменениеПакетаПредложений
+	parts, err = Split(offers, SplitLimits{})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(parts))
+	assert.Nil(t, parts[0].ИзменениеПакетаПредложений)
+	assert.Nil(t, parts[1].ПакетПредложений)
+	assert.Equal(t, x.ИзменениеПакетаПредложений, parts[1].ИзменениеПакетаПредложений)
+}
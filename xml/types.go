@@ -35,6 +35,9 @@ type (
 		Классификатор        *Классификатор    `xml:",omitempty"`
 		Каталог              *Каталог          `xml:",omitempty"`
 		ПакетПредложений     *ПакетПредложений `xml:",omitempty"`
+		// ИзменениеПакетаПредложений схемы 3.x: цены (prices.xml) или остатки (rests.xml)
+		ИзменениеПакетаПредложений *ИзменениеПакетаПредложений `xml:",omitempty"`
+		Документ                   []Документ                  `xml:",omitempty"`
 	}
 
 	// ////////////////////////////////////////////////////////////////////////////
@@ -44,8 +47,8 @@ type (
 		Ид           string
 		Наименование string
 		Владелец     Владелец
-		Группы       []Группа `xml:"Группы>Группа"`
-		Свойства     struct{} // TODO wtf?
+		Группы       []Группа    `xml:"Группы>Группа"`
+		Свойства     *[]Свойство `xml:"Свойства>Свойство,omitempty"`
 	}
 
 	// Группа товаров. Может содержать вложенные группы
@@ -55,6 +58,20 @@ type (
 		Группы       *[]Группа `xml:"Группы>Группа,omitempty"`
 	}
 
+	// Свойство товаров классификатора
+	Свойство struct {
+		Ид               string
+		Наименование     string
+		ТипЗначений      string            `xml:",omitempty"` // Строка, Число, Справочник
+		ВариантыЗначений []ВариантЗначения `xml:"ВариантыЗначений>Справочник,omitempty"`
+	}
+
+	// ВариантЗначения свойства с типом значений Справочник
+	ВариантЗначения struct {
+		ИдЗначения string
+		Значение   string
+	}
+
 	// ////////////////////////////////////////////////////////////////////////////
 
 	// Каталог содержит товары
@@ -68,14 +85,40 @@ type (
 
 	// Товар ...
 	Товар struct {
+		Статус             string `xml:",attr,omitempty"` // Новый, Изменен, Удален
 		Ид                 string
+		ПометкаУдаления    bool   `xml:",omitempty"`
+		Штрихкод           string `xml:",omitempty"`
 		Артикул            string
 		Наименование       string
 		БазоваяЕдиница     БазоваяЕдиница
 		Группы             []string            `xml:"Группы>Ид"`
-		ЗначенияСвойств    struct{}            // TODO wtf?
+		Описание           string              `xml:",omitempty"`
+		Картинка           []string            `xml:",omitempty"` // import_files/ab/abcd.jpg
+		Страна             string              `xml:",omitempty"`
+		Изготовитель       *Изготовитель       `xml:",omitempty"`
+		ЗначенияСвойств    *[]ЗначенияСвойства `xml:"ЗначенияСвойств>ЗначенияСвойства,omitempty"`
+		СтавкиНалогов      *[]СтавкаНалога     `xml:"СтавкиНалогов>СтавкаНалога,omitempty"`
 		ЗначенияРеквизитов []ЗначениеРеквизита `xml:"ЗначенияРеквизитов>ЗначениеРеквизита"`
-		Картинка           string              `xml:",omitempty"`
+	}
+
+	// Изготовитель товара
+	Изготовитель struct {
+		Ид                      string `xml:",omitempty"`
+		Наименование            string
+		ОфициальноеНаименование string `xml:",omitempty"`
+	}
+
+	// ЗначенияСвойства товара. Для свойства типа Справочник Значение содержит ИдЗначения
+	ЗначенияСвойства struct {
+		Ид       string
+		Значение string
+	}
+
+	// СтавкаНалога товара, например НДС 20
+	СтавкаНалога struct {
+		Наименование string
+		Ставка       string // 20, 10, 0, Без НДС
 	}
 
 	// ЗначениеРеквизита ...
@@ -116,24 +159,33 @@ type (
 		Наименование string
 	}
 
-	// Предложение товара содержит остатки и цены
+	// Предложение товара содержит остатки и цены.
+	// Предложение характеристики товара имеет Ид вида "ИдТовара#ИдХарактеристики"
 	Предложение struct {
-		Ид             string
-		Артикул        string
-		Наименование   string
-		БазоваяЕдиница БазоваяЕдиница
-		Цены           []Цена `xml:"Цены>Цена"`
-		Количество     string
-		Склад          []Остаток `xml:"Склад"`
+		Ид                   string
+		Артикул              string
+		Наименование         string
+		БазоваяЕдиница       БазоваяЕдиница
+		ХарактеристикиТовара *[]ХарактеристикаТовара `xml:"ХарактеристикиТовара>ХарактеристикаТовара,omitempty"`
+		Цены                 []Цена                  `xml:"Цены>Цена"`
+		Количество           string                  `xml:",omitempty"`
+		Склад                []Остаток               `xml:"Склад"`
+	}
+
+	// ХарактеристикаТовара варианта товара (размер, цвет...)
+	ХарактеристикаТовара struct {
+		Ид           string `xml:",omitempty"`
+		Наименование string
+		Значение     string
 	}
 
 	// Цена ...
 	Цена struct {
 		ИдТипаЦены    string
 		ЦенаЗаЕдиницу string
-		Валюта        string
-		Единица       string
-		Коэффициент   string
+		Валюта        string `xml:",omitempty"`
+		Единица       string `xml:",omitempty"`
+		Коэффициент   string `xml:",omitempty"`
 	}
 
 	// Остаток на складе
@@ -143,29 +195,143 @@ type (
 		Склад              string `xml:",innerxml"`
 	}
 
+	// ИзменениеПакетаПредложений содержит только цены или только остатки предложений
+	ИзменениеПакетаПредложений struct {
+		СодержитТолькоИзменения bool `xml:",attr"`
+		Ид                      string
+		Наименование            string
+		ИдКаталога              string
+		ИдКлассификатора        string
+		Предложения             []ИзменениеПредложения `xml:"Предложения>Предложение"`
+	}
+
+	// ИзменениеПредложения - цены или остатки предложения
+	ИзменениеПредложения struct {
+		Ид      string
+		Цены    *[]Цена          `xml:"Цены>Цена,omitempty"`
+		Остатки *[]ОстатокТовара `xml:"Остатки>Остаток,omitempty"`
+	}
+
+	// ОстатокТовара на складе или общий, если Склад не указан
+	ОстатокТовара struct {
+		Склад      *ОстатокНаСкладе `xml:",omitempty"`
+		Количество string           `xml:",omitempty"`
+	}
+
+	// ОстатокНаСкладе ...
+	ОстатокНаСкладе struct {
+		Ид         string
+		Количество string
+	}
+
 	// ////////////////////////////////////////////////////////////////////////////
 
-	// Владелец ...
-	Владелец struct {
-		Ид               string
-		Наименование     string
-		Контакты         *[]Контакт `xml:"Контакты>Контакт,omitempty"`
-		ЮридическийАдрес *[]string  `xml:"ЮридическийАдрес>Представление,omitempty"`
-		ИНН              string     `xml:",omitempty"`
-		КПП              string     `xml:",omitempty"`
+	// Владелец каталога или пакета предложений
+	Владелец = Контрагент
+
+	// Контрагент - владелец или участник документа
+	Контрагент struct {
+		Ид                      string
+		Наименование            string
+		Роль                    string           `xml:",omitempty"` // Покупатель, Продавец...
+		ПолноеНаименование      string           `xml:",omitempty"`
+		ОфициальноеНаименование string           `xml:",omitempty"`
+		ЮридическийАдрес        *Адрес           `xml:",omitempty"`
+		ИНН                     string           `xml:",omitempty"`
+		КПП                     string           `xml:",omitempty"`
+		ОКПО                    string           `xml:",omitempty"`
+		РасчетныеСчета          *[]РасчетныйСчет `xml:"РасчетныеСчета>РасчетныйСчет,omitempty"`
+		Адрес                   *Адрес           `xml:",omitempty"`
+		Контакты                *[]Контакт       `xml:"Контакты>Контакт,omitempty"`
+		Представители           *[]Представитель `xml:"Представители>Представитель,omitempty"`
 	}
 
-	// Контакт ...
-	Контакт struct {
+	// Адрес в виде строки Представление и/или разбитый на поля
+	Адрес struct {
+		Представление string         `xml:",omitempty"`
+		АдресноеПоле  []АдресноеПоле `xml:",omitempty"`
+	}
+
+	// АдресноеПоле - часть адреса: Почтовый индекс, Страна, Регион, Город, Улица, Дом...
+	АдресноеПоле struct {
 		Тип      string
 		Значение string
 	}
 
+	// Контакт ...
+	Контакт struct {
+		Тип         string // Телефон рабочий, Почта...
+		Значение    string
+		Комментарий string `xml:",omitempty"`
+	}
+
+	// РасчетныйСчет контрагента
+	РасчетныйСчет struct {
+		НомерСчета  string
+		Банк        *Банк  `xml:",omitempty"`
+		Комментарий string `xml:",omitempty"`
+	}
+
+	// Банк расчетного счета
+	Банк struct {
+		Наименование          string
+		СчетКорреспондентский string `xml:",omitempty"`
+		Адрес                 *Адрес `xml:",omitempty"`
+		БИК                   string `xml:",omitempty"`
+	}
+
+	// Представитель контрагента (контактное лицо, руководитель...)
+	Представитель struct {
+		Отношение    string // Контактное лицо, Руководитель...
+		Ид           string
+		Наименование string
+	}
+
+	// ////////////////////////////////////////////////////////////////////////////
+
+	// Документ (заказ) обмена информацией о заказах
+	Документ struct {
+		Ид                 string
+		Номер              string
+		Дата               string
+		ХозОперация        string // Заказ товара
+		Роль               string // Продавец
+		Валюта             string
+		Курс               string `xml:",omitempty"`
+		Сумма              string
+		Контрагенты        *[]Контрагент        `xml:"Контрагенты>Контрагент,omitempty"`
+		Время              string               `xml:",omitempty"`
+		Комментарий        string               `xml:",omitempty"`
+		Товары             *[]ТоварДокумента    `xml:"Товары>Товар,omitempty"`
+		ЗначенияРеквизитов *[]ЗначениеРеквизита `xml:"ЗначенияРеквизитов>ЗначениеРеквизита,omitempty"`
+	}
+
+	// ТоварДокумента - строка заказа
+	ТоварДокумента struct {
+		Ид                 string
+		Артикул            string `xml:",omitempty"`
+		Наименование       string
+		БазоваяЕдиница     *БазоваяЕдиница `xml:",omitempty"`
+		ЦенаЗаЕдиницу      string          `xml:",omitempty"`
+		Количество         string
+		Сумма              string
+		ЗначенияРеквизитов *[]ЗначениеРеквизита `xml:"ЗначенияРеквизитов>ЗначениеРеквизита,omitempty"`
+	}
+
 	// ////////////////////////////////////////////////////////////////////////////
 
 	// БазоваяЕдиница ...
 	БазоваяЕдиница struct {
-		НаименованиеПолное string `xml:",attr"`
-		БазоваяЕдиница     string `xml:",chardata"`
+		Код                     string     `xml:",attr,omitempty"`
+		НаименованиеПолное      string     `xml:",attr"`
+		МеждународноеСокращение string     `xml:",attr,omitempty"`
+		БазоваяЕдиница          string     `xml:",chardata"`
+		Пересчет                []Пересчет `xml:",omitempty"`
+	}
+
+	// Пересчет единицы в базовую: Коэффициент базовых единиц в Единице
+	Пересчет struct {
+		Единица     string
+		Коэффициент string
 	}
 )
@@ -0,0 +1,97 @@
+package xml
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// roundTrip writes x and reads it back
+func roundTrip(t *testing.T, x *КоммерческаяИнформация) *КоммерческаяИнформация {
+	var buf bytes.Buffer
+	assert.Nil(t, Write(x, &buf))
+	y, err := Read(&buf)
+	assert.Nil(t, err)
+	return y
+}
+
+func TestImportRoundTrip(t *testing.T) {
+	x, err := ReadFile("testdata/import.xml")
+	assert.Nil(t, err)
+	assert.Equal(t, x, roundTrip(t, x))
+
+	products := x.Каталог.Товары
+	assert.Equal(t, 4, len(products))
+
+	p := products[0]
+	assert.Equal(t, "4600000000011", p.Штрихкод)
+	assert.Equal(t, "Лёгкое платье из хлопка; длина миди", p.Описание)
+	assert.Equal(t, []string{
+		"import_files/c5/c5e4a1b03d2e11e980d40cc47a7c2f11_a1.jpg",
+		"import_files/c5/c5e4a1b03d2e11e980d40cc47a7c2f11_a2.jpg",
+	}, p.Картинка)
+	assert.Equal(t, "РОССИЯ", p.Страна)
+	assert.Equal(t, "Швейная фабрика", p.Изготовитель.Наименование)
+	assert.Equal(t, `ООО "Швейная фабрика"`, p.Изготовитель.ОфициальноеНаименование)
+	assert.Equal(t, &[]СтавкаНалога{{Наименование: "НДС", Ставка: "20"}}, p.СтавкиНалогов)
+	assert.False(t, p.Deleted())
+
+	vat, found := p.Tax("НДС")
+	assert.True(t, found)
+	assert.Equal(t, "20", vat)
+	weight, found := p.Requisite("Вес")
+	assert.True(t, found)
+	assert.Equal(t, "0.35", weight)
+	_, found = p.Requisite("Цвет")
+	assert.False(t, found)
+
+	props := x.Классификатор.Properties()
+	assert.Equal(t, 2, len(props))
+	brand, found := p.Property(props[0].Ид)
+	assert.True(t, found)
+	assert.Equal(t, "Лето", props[0].Value(brand))
+	color, found := p.Property(props[1].Ид)
+	assert.True(t, found)
+	assert.Equal(t, "Синий", props[1].Value(color))
+	_, found = products[1].Property(props[1].Ид)
+	assert.False(t, found)
+
+	assert.Nil(t, products[2].Изготовитель)
+	assert.Nil(t, products[2].Картинка)
+
+	deleted := products[3]
+	assert.True(t, deleted.ПометкаУдаления)
+	assert.Equal(t, СтатусУдален, deleted.Статус)
+	assert.True(t, deleted.Deleted())
+	assert.True(t, (&Товар{Статус: СтатусУдален}).Deleted())
+
+	// absent lists are not written as empty parents
+	var buf bytes.Buffer
+	assert.Nil(t, Write(&КоммерческаяИнформация{Каталог: &Каталог{Товары: []Товар{{Ид: "p"}}}}, &buf))
+	assert.NotContains(t, buf.String(), "ЗначенияСвойств")
+	assert.NotContains(t, buf.String(), "СтавкиНалогов")
+	buf.Reset()
+	assert.Nil(t, Write(&КоммерческаяИнформация{Классификатор: &Классификатор{Ид: "cls"}}, &buf))
+	assert.NotContains(t, buf.String(), "Свойства")
+	_, found = (&Товар{}).Tax("НДС")
+	assert.False(t, found)
+}
+
+func TestOffersRoundTrip(t *testing.T) {
+	x, err := ReadFile("testdata/offers.xml")
+	assert.Nil(t, err)
+	assert.Equal(t, x, roundTrip(t, x))
+
+	pack := x.ПакетПредложений
+	assert.Equal(t, 2, len(pack.ТипыЦен))
+	assert.True(t, pack.ТипыЦен[0].Налог.УчтеноВСумме)
+	assert.Equal(t, 2, len(pack.Склады))
+	assert.Equal(t, 4, len(pack.Предложения))
+
+	o := pack.Предложения[0]
+	assert.Equal(t, "c5e4a1b0-3d2e-11e9-80d4-0cc47a7c2f11", o.ProductID())
+	assert.Equal(t, "Размер: 44, Цвет: голубой", o.Characteristics())
+	assert.Equal(t, 2, len(o.Цены))
+	assert.Equal(t, "5", o.Склад[0].КоличествоНаСкладе)
+}
@@ -0,0 +1,124 @@
+package xml
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/sevkin/go-cml/okei"
+)
+
+// UnmarshalXML trims chardata of element holding Пересчет,
+// otherwise indents around Пересчет grow with every Write and Read
+func (u *БазоваяЕдиница) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type unit БазоваяЕдиница
+	if err := d.DecodeElement((*unit)(u), &start); err != nil {
+		return err
+	}
+	u.БазоваяЕдиница = strings.TrimSpace(u.БазоваяЕдиница)
+	return nil
+}
+
+// Name returns short name of unit (шт)
+func (u *БазоваяЕдиница) Name() string {
+	return strings.TrimSpace(u.БазоваяЕдиница)
+}
+
+// Unit returns registry unit by Код or by name
+func (u *БазоваяЕдиница) Unit() (okei.Unit, bool) {
+	if len(u.Код) > 0 {
+		if unit, found := okei.Lookup(u.Код); found {
+			return unit, true
+		}
+	}
+	for _, name := range []string{u.Name(), u.МеждународноеСокращение, u.НаименованиеПолное} {
+		if unit, found := okei.Find(name); found {
+			return unit, true
+		}
+	}
+	return okei.Unit{}, false
+}
+
+// Is reports whether unit (code or name) is the base one
+func (u *БазоваяЕдиница) Is(unit string) bool {
+	for _, name := range []string{u.Код, u.Name(), u.МеждународноеСокращение, u.НаименованиеПолное} {
+		if okei.Same(unit, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// Ratio returns number of base units in unit: 1 for empty or base unit,
+// Коэффициент of Пересчет for the others
+func (u *БазоваяЕдиница) Ratio(unit string) (float64, bool) {
+	if len(strings.TrimSpace(unit)) == 0 || u.Is(unit) {
+		return 1, true
+	}
+	for _, p := range u.Пересчет {
+		if okei.Same(unit, p.Единица) {
+			ratio, err := decimal(p.Коэффициент)
+			return ratio, err == nil && ratio > 0
+		}
+	}
+	return 0, false
+}
+
+// decimal parses CommerceML number which may have comma separator
+func decimal(s string) (float64, error) {
+	return strconv.ParseFloat(strings.Replace(strings.TrimSpace(s), ",", ".", 1), 64)
+}
+
+// Ratio returns number of base units in Единица of price,
+// Коэффициент or Пересчет of base unit when it is missing
+func (p *Цена) Ratio(base *БазоваяЕдиница) (float64, error) {
+	if len(strings.TrimSpace(p.Коэффициент)) > 0 {
+		ratio, err := decimal(p.Коэффициент)
+		if err != nil {
+			return 0, fmt.Errorf("price: Коэффициент %q: %v", p.Коэффициент, err)
+		}
+		if ratio <= 0 {
+			return 0, fmt.Errorf("price: Коэффициент %q is not positive", p.Коэффициент)
+		}
+		return ratio, nil
+	}
+	if len(strings.TrimSpace(p.Единица)) == 0 {
+		return 1, nil
+	}
+	if base != nil {
+		if ratio, found := base.Ratio(p.Единица); found {
+			return ratio, nil
+		}
+	}
+	return 0, fmt.Errorf("price: unknown ratio of unit %q", p.Единица)
+}
+
+// BasePrice returns ЦенаЗаЕдиницу per base unit
+func (p *Цена) BasePrice(base *БазоваяЕдиница) (float64, error) {
+	return p.Convert(base, "")
+}
+
+// Convert returns ЦенаЗаЕдиницу per unit (code or name) known to base,
+// empty unit is the base one
+func (p *Цена) Convert(base *БазоваяЕдиница, unit string) (float64, error) {
+	value, err := decimal(p.ЦенаЗаЕдиницу)
+	if err != nil {
+		return 0, fmt.Errorf("price: ЦенаЗаЕдиницу %q: %v", p.ЦенаЗаЕдиницу, err)
+	}
+	from, err := p.Ratio(base)
+	if err != nil {
+		return 0, err
+	}
+	to := 1.0
+	if len(strings.TrimSpace(unit)) > 0 {
+		if base == nil {
+			return 0, fmt.Errorf("price: unknown ratio of unit %q", unit)
+		}
+		var found bool
+		if to, found = base.Ratio(unit); !found {
+			return 0, fmt.Errorf("price: unknown ratio of unit %q", unit)
+		}
+	}
+	return value / from * to, nil
+}
@@ -0,0 +1,96 @@
+package xml
+
+import (
+	"encoding/xml"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnitParse(t *testing.T) {
+	p := ReadMust("testdata/import.xml").Каталог.Товары[0]
+	assert.Equal(t, БазоваяЕдиница{Код: "796", НаименованиеПолное: "Штука",
+		МеждународноеСокращение: "PCE", БазоваяЕдиница: "шт"}, p.БазоваяЕдиница)
+
+	var u БазоваяЕдиница
+	assert.Nil(t, xml.Unmarshal([]byte(`<БазоваяЕдиница Код="796" НаименованиеПолное="Штука">
+		шт
+		<Пересчет><Единица>778</Единица><Коэффициент>10</Коэффициент></Пересчет>
+	</БазоваяЕдиница>`), &u))
+	assert.Equal(t, "шт", u.БазоваяЕдиница)
+	assert.Equal(t, []Пересчет{{Единица: "778", Коэффициент: "10"}}, u.Пересчет)
+
+	// written and read back unit is not changed
+	x := &КоммерческаяИнформация{Каталог: &Каталог{Товары: []Товар{{Ид: "p", БазоваяЕдиница: u}}}}
+	y := roundTrip(t, roundTrip(t, x))
+	assert.Equal(t, u, y.Каталог.Товары[0].БазоваяЕдиница)
+	d, s := Diff(x, y)
+	assert.True(t, s.Empty())
+	assert.Equal(t, 0, len(d.Каталог.Товары))
+
+	unit, found := u.Unit()
+	assert.True(t, found)
+	assert.Equal(t, "PCE", unit.International)
+	_, found = (&БазоваяЕдиница{БазоваяЕдиница: "бочка"}).Unit()
+	assert.False(t, found)
+	unit, _ = (&БазоваяЕдиница{БазоваяЕдиница: "кг"}).Unit()
+	assert.Equal(t, "166", unit.Code)
+}
+
+func TestUnitRatio(t *testing.T) {
+	u := &БазоваяЕдиница{Код: "796", БазоваяЕдиница: "шт", Пересчет: []Пересчет{
+		{Единица: "778", Коэффициент: "10"},
+		{Единица: "ящ", Коэффициент: "0"},
+	}}
+	for _, c := range []struct {
+		unit  string
+		ratio float64
+		found bool
+	}{
+		{"", 1, true},
+		{"шт", 1, true},
+		{"PCE", 1, true},
+		{"упак", 10, true},
+		{"778", 10, true},
+		{"ящ", 0, false},
+		{"кг", 0, false},
+	} {
+		ratio, found := u.Ratio(c.unit)
+		assert.Equal(t, c.ratio, ratio, c.unit)
+		assert.Equal(t, c.found, found, c.unit)
+	}
+}
+
+func TestPriceConvert(t *testing.T) {
+	u := &БазоваяЕдиница{Код: "796", БазоваяЕдиница: "шт", Пересчет: []Пересчет{
+		{Единица: "778", Коэффициент: "10"},
+		{Единица: "798", Коэффициент: "1000"},
+	}}
+	for _, c := range []struct {
+		name  string
+		price Цена
+		unit  string
+		value float64
+		err   bool
+	}{
+		{"base", Цена{ЦенаЗаЕдиницу: "12,50"}, "", 12.5, false},
+		{"to package", Цена{ЦенаЗаЕдиницу: "12.50", Единица: "шт"}, "упак", 125, false},
+		{"by ratio", Цена{ЦенаЗаЕдиницу: "120", Единица: "упак", Коэффициент: "10"}, "", 12, false},
+		{"by conversion", Цена{ЦенаЗаЕдиницу: "120", Единица: "упак"}, "шт", 12, false},
+		{"package to thousand", Цена{ЦенаЗаЕдиницу: "120", Единица: "778"}, "тыс. шт", 12000, false},
+		{"unknown price unit", Цена{ЦенаЗаЕдиницу: "120", Единица: "ящ"}, "", 0, true},
+		{"unknown unit", Цена{ЦенаЗаЕдиницу: "120"}, "кг", 0, true},
+		{"bad ratio", Цена{ЦенаЗаЕдиницу: "120", Коэффициент: "0"}, "", 0, true},
+		{"bad price", Цена{ЦенаЗаЕдиницу: "дорого"}, "", 0, true},
+	} {
+		value, err := c.price.Convert(u, c.unit)
+		assert.Equal(t, c.err, err != nil, c.name)
+		assert.InDelta(t, c.value, value, 1e-9, c.name)
+	}
+
+	value, err := (&Цена{ЦенаЗаЕдиницу: "30", Коэффициент: "3"}).BasePrice(nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 10.0, value)
+	_, err = (&Цена{ЦенаЗаЕдиницу: "30", Единица: "упак"}).BasePrice(nil)
+	assert.NotNil(t, err)
+}
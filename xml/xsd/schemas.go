@@ -0,0 +1,486 @@
+package xsd
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// cml2 is the subset of CommerceML 2.0x schema
+// http://v8.1c.ru/edi/edi_stnd/90/92.htm
+// covering elements of package xml and orders.
+// Element order follows the official schema
+const cml2 = `<?xml version="1.0" encoding="UTF-8"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" elementFormDefault="qualified">
+
+  <xs:element name="КоммерческаяИнформация">
+    <xs:complexType>
+      <xs:sequence>
+        <xs:element name="Классификатор" type="Классификатор" minOccurs="0"/>
+        <xs:element name="Каталог" type="Каталог" minOccurs="0"/>
+        <xs:element name="ПакетПредложений" type="ПакетПредложений" minOccurs="0"/>
+        <xs:element name="Документ" type="Документ" minOccurs="0" maxOccurs="unbounded"/>
+      </xs:sequence>
+      <xs:attribute name="ВерсияСхемы" type="xs:string" use="required"/>
+      <xs:attribute name="ДатаФормирования" type="xs:dateTime" use="required"/>
+      <xs:attribute name="СинхронизацияТоваров" type="xs:boolean"/>
+    </xs:complexType>
+  </xs:element>
+
+  <xs:simpleType name="Количество">
+    <xs:restriction base="xs:decimal"/>
+  </xs:simpleType>
+
+  <xs:simpleType name="Сумма">
+    <xs:restriction base="xs:decimal"/>
+  </xs:simpleType>
+
+  <xs:simpleType name="СтатусТовара">
+    <xs:restriction base="xs:string">
+      <xs:enumeration value="Новый"/>
+      <xs:enumeration value="Изменен"/>
+      <xs:enumeration value="Удален"/>
+    </xs:restriction>
+  </xs:simpleType>
+
+  <xs:complexType name="Классификатор">
+    <xs:sequence>
+      <xs:element name="Ид" type="xs:string"/>
+      <xs:element name="Наименование" type="xs:string"/>
+      <xs:element name="Владелец" type="Контрагент"/>
+      <xs:element name="Описание" type="xs:string" minOccurs="0"/>
+      <xs:element name="Группы" minOccurs="0">
+        <xs:complexType>
+          <xs:sequence>
+            <xs:element name="Группа" type="Группа" minOccurs="0" maxOccurs="unbounded"/>
+          </xs:sequence>
+        </xs:complexType>
+      </xs:element>
+      <xs:element name="Свойства" minOccurs="0">
+        <xs:complexType>
+          <xs:sequence>
+            <xs:element name="Свойство" type="Свойство" minOccurs="0" maxOccurs="unbounded"/>
+          </xs:sequence>
+        </xs:complexType>
+      </xs:element>
+      <xs:element name="ТипыЦен" minOccurs="0">
+        <xs:complexType>
+          <xs:sequence>
+            <xs:element name="ТипЦены" type="ТипЦены" minOccurs="0" maxOccurs="unbounded"/>
+          </xs:sequence>
+        </xs:complexType>
+      </xs:element>
+    </xs:sequence>
+  </xs:complexType>
+
+  <xs:complexType name="Группа">
+    <xs:sequence>
+      <xs:element name="Ид" type="xs:string"/>
+      <xs:element name="ПометкаУдаления" type="xs:boolean" minOccurs="0"/>
+      <xs:element name="Наименование" type="xs:string"/>
+      <xs:element name="Описание" type="xs:string" minOccurs="0"/>
+      <xs:element name="Группы" minOccurs="0">
+        <xs:complexType>
+          <xs:sequence>
+            <xs:element name="Группа" type="Группа" minOccurs="0" maxOccurs="unbounded"/>
+          </xs:sequence>
+        </xs:complexType>
+      </xs:element>
+    </xs:sequence>
+  </xs:complexType>
+
+  <xs:complexType name="Свойство">
+    <xs:sequence>
+      <xs:element name="Ид" type="xs:string"/>
+      <xs:element name="Наименование" type="xs:string"/>
+      <xs:element name="Обязательное" type="xs:boolean" minOccurs="0"/>
+      <xs:element name="Множественное" type="xs:boolean" minOccurs="0"/>
+      <xs:element name="ТипЗначений" type="xs:string" minOccurs="0"/>
+      <xs:element name="ВариантыЗначений" minOccurs="0"/>
+    </xs:sequence>
+  </xs:complexType>
+
+  <xs:complexType name="Каталог">
+    <xs:sequence>
+      <xs:element name="Ид" type="xs:string"/>
+      <xs:element name="ИдКлассификатора" type="xs:string"/>
+      <xs:element name="Наименование" type="xs:string"/>
+      <xs:element name="Владелец" type="Контрагент" minOccurs="0"/>
+      <xs:element name="Товары" minOccurs="0">
+        <xs:complexType>
+          <xs:sequence>
+            <xs:element name="Товар" type="Товар" minOccurs="0" maxOccurs="unbounded"/>
+          </xs:sequence>
+        </xs:complexType>
+      </xs:element>
+      <xs:element name="Описание" type="xs:string" minOccurs="0"/>
+    </xs:sequence>
+    <xs:attribute name="СодержитТолькоИзменения" type="xs:boolean"/>
+  </xs:complexType>
+
+  <xs:complexType name="Товар">
+    <xs:sequence>
+      <xs:element name="Ид" type="xs:string"/>
+      <xs:element name="ПометкаУдаления" type="xs:boolean" minOccurs="0"/>
+      <xs:element name="Штрихкод" type="xs:string" minOccurs="0"/>
+      <xs:element name="Артикул" type="xs:string" minOccurs="0"/>
+      <xs:element name="Наименование" type="xs:string"/>
+      <xs:element name="БазоваяЕдиница" type="БазоваяЕдиница" minOccurs="0"/>
+      <xs:element name="Группы" minOccurs="0">
+        <xs:complexType>
+          <xs:sequence>
+            <xs:element name="Ид" type="xs:string" minOccurs="0" maxOccurs="unbounded"/>
+          </xs:sequence>
+        </xs:complexType>
+      </xs:element>
+      <xs:element name="Описание" type="xs:string" minOccurs="0"/>
+      <xs:element name="Картинка" type="xs:string" minOccurs="0" maxOccurs="unbounded"/>
+      <xs:element name="Страна" type="xs:string" minOccurs="0"/>
+      <xs:element name="Изготовитель" type="Изготовитель" minOccurs="0"/>
+      <xs:element name="ЗначенияСвойств" minOccurs="0">
+        <xs:complexType>
+          <xs:sequence>
+            <xs:element name="ЗначенияСвойства" type="ЗначенияСвойства" minOccurs="0" maxOccurs="unbounded"/>
+          </xs:sequence>
+        </xs:complexType>
+      </xs:element>
+      <xs:element name="СтавкиНалогов" minOccurs="0">
+        <xs:complexType>
+          <xs:sequence>
+            <xs:element name="СтавкаНалога" type="СтавкаНалога" minOccurs="0" maxOccurs="unbounded"/>
+          </xs:sequence>
+        </xs:complexType>
+      </xs:element>
+      <xs:element name="ХарактеристикиТовара" type="ХарактеристикиТовара" minOccurs="0"/>
+      <xs:element name="ЗначенияРеквизитов" type="ЗначенияРеквизитов" minOccurs="0"/>
+    </xs:sequence>
+    <xs:attribute name="Статус" type="СтатусТовара"/>
+  </xs:complexType>
+
+  <xs:complexType name="БазоваяЕдиница">
+    <xs:simpleContent>
+      <xs:extension base="xs:string">
+        <xs:attribute name="Код" type="xs:string"/>
+        <xs:attribute name="НаименованиеПолное" type="xs:string"/>
+        <xs:attribute name="МеждународноеСокращение" type="xs:string"/>
+      </xs:extension>
+    </xs:simpleContent>
+  </xs:complexType>
+
+  <xs:complexType name="Изготовитель">
+    <xs:sequence>
+      <xs:element name="Ид" type="xs:string" minOccurs="0"/>
+      <xs:element name="Наименование" type="xs:string"/>
+      <xs:element name="ОфициальноеНаименование" type="xs:string" minOccurs="0"/>
+    </xs:sequence>
+  </xs:complexType>
+
+  <xs:complexType name="ЗначенияСвойства">
+    <xs:sequence>
+      <xs:element name="Ид" type="xs:string"/>
+      <xs:element name="Значение" type="xs:string" minOccurs="0" maxOccurs="unbounded"/>
+    </xs:sequence>
+  </xs:complexType>
+
+  <xs:complexType name="СтавкаНалога">
+    <xs:sequence>
+      <xs:element name="Наименование" type="xs:string"/>
+      <xs:element name="Ставка" type="xs:string"/>
+    </xs:sequence>
+  </xs:complexType>
+
+  <xs:complexType name="ХарактеристикиТовара">
+    <xs:sequence>
+      <xs:element name="ХарактеристикаТовара" minOccurs="0" maxOccurs="unbounded">
+        <xs:complexType>
+          <xs:sequence>
+            <xs:element name="Ид" type="xs:string" minOccurs="0"/>
+            <xs:element name="Наименование" type="xs:string"/>
+            <xs:element name="Значение" type="xs:string"/>
+          </xs:sequence>
+        </xs:complexType>
+      </xs:element>
+    </xs:sequence>
+  </xs:complexType>
+
+  <xs:complexType name="ЗначенияРеквизитов">
+    <xs:sequence>
+      <xs:element name="ЗначениеРеквизита" minOccurs="0" maxOccurs="unbounded">
+        <xs:complexType>
+          <xs:sequence>
+            <xs:element name="Наименование" type="xs:string"/>
+            <xs:element name="Значение" type="xs:string" maxOccurs="unbounded"/>
+          </xs:sequence>
+        </xs:complexType>
+      </xs:element>
+    </xs:sequence>
+  </xs:complexType>
+
+  <xs:complexType name="ПакетПредложений">
+    <xs:sequence>
+      <xs:element name="Ид" type="xs:string"/>
+      <xs:element name="Наименование" type="xs:string"/>
+      <xs:element name="ИдКаталога" type="xs:string"/>
+      <xs:element name="ИдКлассификатора" type="xs:string" minOccurs="0"/>
+      <xs:element name="Владелец" type="Контрагент"/>
+      <xs:element name="ТипыЦен" minOccurs="0">
+        <xs:complexType>
+          <xs:sequence>
+            <xs:element name="ТипЦены" type="ТипЦены" minOccurs="0" maxOccurs="unbounded"/>
+          </xs:sequence>
+        </xs:complexType>
+      </xs:element>
+      <xs:element name="Склады" minOccurs="0">
+        <xs:complexType>
+          <xs:sequence>
+            <xs:element name="Склад" type="Склад" minOccurs="0" maxOccurs="unbounded"/>
+          </xs:sequence>
+        </xs:complexType>
+      </xs:element>
+      <xs:element name="Предложения" minOccurs="0">
+        <xs:complexType>
+          <xs:sequence>
+            <xs:element name="Предложение" type="Предложение" minOccurs="0" maxOccurs="unbounded"/>
+          </xs:sequence>
+        </xs:complexType>
+      </xs:element>
+    </xs:sequence>
+    <xs:attribute name="СодержитТолькоИзменения" type="xs:boolean"/>
+  </xs:complexType>
+
+  <xs:complexType name="ТипЦены">
+    <xs:sequence>
+      <xs:element name="Ид" type="xs:string"/>
+      <xs:element name="Наименование" type="xs:string"/>
+      <xs:element name="Валюта" type="xs:string" minOccurs="0"/>
+      <xs:element name="Налог" minOccurs="0">
+        <xs:complexType>
+          <xs:sequence>
+            <xs:element name="Наименование" type="xs:string"/>
+            <xs:element name="УчтеноВСумме" type="xs:boolean"/>
+            <xs:element name="Акциз" type="xs:boolean" minOccurs="0"/>
+          </xs:sequence>
+        </xs:complexType>
+      </xs:element>
+    </xs:sequence>
+  </xs:complexType>
+
+  <xs:complexType name="Склад">
+    <xs:sequence>
+      <xs:element name="Ид" type="xs:string"/>
+      <xs:element name="Наименование" type="xs:string"/>
+      <xs:element name="Адрес" type="Адрес" minOccurs="0"/>
+      <xs:element name="Контакты" type="Контакты" minOccurs="0"/>
+    </xs:sequence>
+  </xs:complexType>
+
+  <xs:complexType name="Предложение">
+    <xs:sequence>
+      <xs:element name="Ид" type="xs:string"/>
+      <xs:element name="Штрихкод" type="xs:string" minOccurs="0"/>
+      <xs:element name="Артикул" type="xs:string" minOccurs="0"/>
+      <xs:element name="Наименование" type="xs:string"/>
+      <xs:element name="БазоваяЕдиница" type="БазоваяЕдиница" minOccurs="0"/>
+      <xs:element name="ХарактеристикиТовара" type="ХарактеристикиТовара" minOccurs="0"/>
+      <xs:element name="ЗначенияРеквизитов" type="ЗначенияРеквизитов" minOccurs="0"/>
+      <xs:element name="Цены" minOccurs="0">
+        <xs:complexType>
+          <xs:sequence>
+            <xs:element name="Цена" type="Цена" minOccurs="0" maxOccurs="unbounded"/>
+          </xs:sequence>
+        </xs:complexType>
+      </xs:element>
+      <xs:element name="Количество" type="Количество" minOccurs="0"/>
+      <xs:element name="Склад" type="ОстатокНаСкладе" minOccurs="0" maxOccurs="unbounded"/>
+    </xs:sequence>
+  </xs:complexType>
+
+  <xs:complexType name="Цена">
+    <xs:sequence>
+      <xs:element name="Представление" type="xs:string" minOccurs="0"/>
+      <xs:element name="ИдТипаЦены" type="xs:string"/>
+      <xs:element name="ЦенаЗаЕдиницу" type="Сумма"/>
+      <xs:element name="Валюта" type="xs:string" minOccurs="0"/>
+      <xs:element name="Единица" type="xs:string" minOccurs="0"/>
+      <xs:element name="Коэффициент" type="xs:decimal" minOccurs="0"/>
+    </xs:sequence>
+  </xs:complexType>
+
+  <!-- Bitrix extension: <Склад ИдСклада="..." КоличествоНаСкладе="..."/> -->
+  <xs:complexType name="ОстатокНаСкладе" mixed="true">
+    <xs:attribute name="ИдСклада" type="xs:string" use="required"/>
+    <xs:attribute name="КоличествоНаСкладе" type="Количество" use="required"/>
+  </xs:complexType>
+
+  <xs:complexType name="Контрагент">
+    <xs:sequence>
+      <xs:element name="Ид" type="xs:string"/>
+      <xs:element name="Наименование" type="xs:string"/>
+      <xs:element name="Роль" type="xs:string" minOccurs="0"/>
+      <xs:element name="ПолноеНаименование" type="xs:string" minOccurs="0"/>
+      <xs:element name="ОфициальноеНаименование" type="xs:string" minOccurs="0"/>
+      <xs:element name="ЮридическийАдрес" type="Адрес" minOccurs="0"/>
+      <xs:element name="ИНН" type="xs:string" minOccurs="0"/>
+      <xs:element name="КПП" type="xs:string" minOccurs="0"/>
+      <xs:element name="ОКПО" type="xs:string" minOccurs="0"/>
+      <xs:element name="РасчетныеСчета" minOccurs="0">
+        <xs:complexType>
+          <xs:sequence>
+            <xs:element name="РасчетныйСчет" type="РасчетныйСчет" minOccurs="0" maxOccurs="unbounded"/>
+          </xs:sequence>
+        </xs:complexType>
+      </xs:element>
+      <xs:element name="Адрес" type="Адрес" minOccurs="0"/>
+      <xs:element name="Контакты" type="Контакты" minOccurs="0"/>
+      <xs:element name="Представители" minOccurs="0">
+        <xs:complexType>
+          <xs:sequence>
+            <xs:element name="Представитель" type="Представитель" minOccurs="0" maxOccurs="unbounded"/>
+          </xs:sequence>
+        </xs:complexType>
+      </xs:element>
+    </xs:sequence>
+  </xs:complexType>
+
+  <xs:complexType name="Адрес">
+    <xs:sequence>
+      <xs:element name="Представление" type="xs:string" minOccurs="0" maxOccurs="unbounded"/>
+      <xs:element name="АдресноеПоле" minOccurs="0" maxOccurs="unbounded">
+        <xs:complexType>
+          <xs:sequence>
+            <xs:element name="Тип" type="xs:string"/>
+            <xs:element name="Значение" type="xs:string"/>
+          </xs:sequence>
+        </xs:complexType>
+      </xs:element>
+    </xs:sequence>
+  </xs:complexType>
+
+  <xs:complexType name="Контакты">
+    <xs:sequence>
+      <xs:element name="Контакт" minOccurs="0" maxOccurs="unbounded">
+        <xs:complexType>
+          <xs:sequence>
+            <xs:element name="Тип" type="xs:string"/>
+            <xs:element name="Значение" type="xs:string"/>
+            <xs:element name="Комментарий" type="xs:string" minOccurs="0"/>
+          </xs:sequence>
+        </xs:complexType>
+      </xs:element>
+    </xs:sequence>
+  </xs:complexType>
+
+  <xs:complexType name="РасчетныйСчет">
+    <xs:sequence>
+      <xs:element name="НомерСчета" type="xs:string"/>
+      <xs:element name="Банк" minOccurs="0">
+        <xs:complexType>
+          <xs:sequence>
+            <xs:element name="Наименование" type="xs:string"/>
+            <xs:element name="СчетКорреспондентский" type="xs:string" minOccurs="0"/>
+            <xs:element name="Адрес" type="Адрес" minOccurs="0"/>
+            <xs:element name="БИК" type="xs:string" minOccurs="0"/>
+          </xs:sequence>
+        </xs:complexType>
+      </xs:element>
+      <xs:element name="Комментарий" type="xs:string" minOccurs="0"/>
+    </xs:sequence>
+  </xs:complexType>
+
+  <xs:complexType name="Представитель">
+    <xs:sequence>
+      <xs:element name="Отношение" type="xs:string"/>
+      <xs:element name="Ид" type="xs:string"/>
+      <xs:element name="Наименование" type="xs:string"/>
+    </xs:sequence>
+  </xs:complexType>
+
+  <xs:complexType name="Документ">
+    <xs:sequence>
+      <xs:element name="Ид" type="xs:string"/>
+      <xs:element name="Номер" type="xs:string"/>
+      <xs:element name="Дата" type="xs:date"/>
+      <xs:element name="ХозОперация" type="xs:string"/>
+      <xs:element name="Роль" type="xs:string"/>
+      <xs:element name="Валюта" type="xs:string"/>
+      <xs:element name="Курс" type="xs:decimal" minOccurs="0"/>
+      <xs:element name="Сумма" type="Сумма"/>
+      <xs:element name="Контрагенты" minOccurs="0">
+        <xs:complexType>
+          <xs:sequence>
+            <xs:element name="Контрагент" type="Контрагент" minOccurs="0" maxOccurs="unbounded"/>
+          </xs:sequence>
+        </xs:complexType>
+      </xs:element>
+      <xs:element name="Время" type="xs:time" minOccurs="0"/>
+      <xs:element name="Комментарий" type="xs:string" minOccurs="0"/>
+      <xs:element name="Товары" minOccurs="0">
+        <xs:complexType>
+          <xs:sequence>
+            <xs:element name="Товар" type="ТоварДокумента" minOccurs="0" maxOccurs="unbounded"/>
+          </xs:sequence>
+        </xs:complexType>
+      </xs:element>
+      <xs:element name="ЗначенияРеквизитов" type="ЗначенияРеквизитов" minOccurs="0"/>
+    </xs:sequence>
+  </xs:complexType>
+
+  <xs:complexType name="ТоварДокумента">
+    <xs:sequence>
+      <xs:element name="Ид" type="xs:string"/>
+      <xs:element name="Артикул" type="xs:string" minOccurs="0"/>
+      <xs:element name="Наименование" type="xs:string"/>
+      <xs:element name="БазоваяЕдиница" type="БазоваяЕдиница" minOccurs="0"/>
+      <xs:element name="ЦенаЗаЕдиницу" type="Сумма" minOccurs="0"/>
+      <xs:element name="Количество" type="Количество"/>
+      <xs:element name="Сумма" type="Сумма"/>
+      <xs:element name="ЗначенияРеквизитов" type="ЗначенияРеквизитов" minOccurs="0"/>
+    </xs:sequence>
+  </xs:complexType>
+
+</xs:schema>
+`
+
+var (
+	// schemas by ВерсияСхемы
+	schemas = map[string]string{
+		"2.03": cml2,
+		"2.04": cml2,
+		"2.05": cml2,
+		"2.07": cml2,
+		"2.08": cml2,
+		"2.09": cml2,
+		"2.10": cml2,
+	}
+
+	parsed   = make(map[string]*Schema)
+	parsedMu sync.Mutex
+)
+
+// Versions returns ВерсияСхемы values having bundled schema
+func Versions() []string {
+	v := make([]string, 0, len(schemas))
+	for k := range schemas {
+		v = append(v, k)
+	}
+	sort.Strings(v)
+	return v
+}
+
+// ForVersion returns bundled schema for ВерсияСхемы
+func ForVersion(version string) (*Schema, error) {
+	text, ok := schemas[version]
+	if !ok {
+		return nil, fmt.Errorf("unsupported ВерсияСхемы %q", version)
+	}
+
+	parsedMu.Lock()
+	defer parsedMu.Unlock()
+
+	if s, ok := parsed[text]; ok {
+		return s, nil
+	}
+	s := MustParse(text)
+	parsed[text] = s
+	return s, nil
+}
@@ -0,0 +1,143 @@
+package xsd
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/html/charset"
+)
+
+type (
+	// node is a decoded xml element with its position in source
+	node struct {
+		name     string
+		attrs    []xml.Attr
+		children []*node
+		text     string
+		line     int
+		column   int
+	}
+
+	// lines maps byte offsets to line:column
+	lines struct {
+		buf    []byte
+		starts []int
+	}
+)
+
+func newLines(buf []byte) *lines {
+	l := &lines{buf: buf, starts: []int{0}}
+	for i, b := range buf {
+		if b == '\n' {
+			l.starts = append(l.starts, i+1)
+		}
+	}
+	return l
+}
+
+// pos returns 1-based line and column (in runes) of offset
+func (l *lines) pos(offset int64) (int, int) {
+	off := int(offset)
+	if off > len(l.buf) {
+		off = len(l.buf)
+	}
+	n := sort.Search(len(l.starts), func(i int) bool { return l.starts[i] > off }) - 1
+	return n + 1, utf8.RuneCount(l.buf[l.starts[n]:off]) + 1
+}
+
+var encodingRe = regexp.MustCompile(`^(?:\xEF\xBB\xBF)?\s*<\?xml[^>]*\sencoding\s*=\s*["']([^"']+)["']`)
+
+// toUTF8 converts buf of encoding declared by prolog to UTF-8
+// so offsets of decoder match lines of the buffer
+func toUTF8(buf []byte) ([]byte, error) {
+	m := encodingRe.FindSubmatch(buf)
+	if m == nil || strings.EqualFold(string(m[1]), "utf-8") {
+		return buf, nil
+	}
+	// 1C exports are often windows-1251 encoded
+	r, err := charset.NewReaderLabel(string(m[1]), bytes.NewReader(buf))
+	if err != nil {
+		return nil, fmt.Errorf("unsupported encoding %q", m[1])
+	}
+	return ioutil.ReadAll(r)
+}
+
+// decode reads whole xml document into the tree of nodes
+func decode(buf []byte) (*node, error) {
+	buf, err := toUTF8(buf)
+	if err != nil {
+		return nil, &Error{Line: 1, Column: 1, Message: err.Error()}
+	}
+	l := newLines(buf)
+	d := xml.NewDecoder(bytes.NewReader(buf))
+	// buf is already converted
+	d.CharsetReader = func(label string, r io.Reader) (io.Reader, error) {
+		return r, nil
+	}
+
+	var (
+		root  *node
+		stack []*node
+		text  []*strings.Builder
+	)
+	for {
+		offset := d.InputOffset()
+		tok, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			line, col := l.pos(d.InputOffset())
+			msg := err.Error()
+			if e, ok := err.(*xml.SyntaxError); ok {
+				msg = e.Msg
+			}
+			return nil, &Error{Line: line, Column: col, Message: msg}
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			n := &node{name: t.Name.Local, attrs: t.Attr}
+			n.line, n.column = l.pos(offset)
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, n)
+			} else {
+				root = n
+			}
+			stack = append(stack, n)
+			text = append(text, new(strings.Builder))
+		case xml.EndElement:
+			n := stack[len(stack)-1]
+			n.text = text[len(text)-1].String()
+			stack = stack[:len(stack)-1]
+			text = text[:len(text)-1]
+		case xml.CharData:
+			if len(text) > 0 {
+				text[len(text)-1].Write(t)
+			}
+		}
+	}
+
+	if root == nil {
+		return nil, &Error{Line: 1, Column: 1, Message: "no root element"}
+	}
+	return root, nil
+}
+
+// attr returns value of attribute by local name
+func (n *node) attr(name string) (string, bool) {
+	for _, a := range n.attrs {
+		if a.Name.Local == name && a.Name.Space != "xmlns" {
+			return a.Value, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,387 @@
+package xsd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"strings"
+
+	cml "github.com/sevkin/go-cml/xml"
+)
+
+type (
+	// Error of validation with position in document
+	Error struct {
+		Line    int
+		Column  int
+		Message string
+	}
+
+	// Errors is the list of all validation errors of document
+	Errors []*Error
+
+	validator struct {
+		schema *Schema
+		errors Errors
+
+		// the furthest position where matcher expected an element
+		expectPos  int
+		expectName []string
+	}
+)
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%d:%d: %s", e.Line, e.Column, e.Message)
+}
+
+func (e Errors) Error() string {
+	msg := make([]string, len(e))
+	for i, err := range e {
+		msg[i] = err.Error()
+	}
+	return strings.Join(msg, "\n")
+}
+
+var builtin = map[string]*regexp.Regexp{
+	"anySimpleType":      regexp.MustCompile(`(?s)^.*$`),
+	"string":             regexp.MustCompile(`(?s)^.*$`),
+	"normalizedString":   regexp.MustCompile(`^[^\t\n\r]*$`),
+	"token":              regexp.MustCompile(`(?s)^.*$`),
+	"boolean":            regexp.MustCompile(`^(true|false|1|0)$`),
+	"decimal":            regexp.MustCompile(`^[+-]?(\d+(\.\d*)?|\.\d+)$`),
+	"integer":            regexp.MustCompile(`^[+-]?\d+$`),
+	"int":                regexp.MustCompile(`^[+-]?\d+$`),
+	"long":               regexp.MustCompile(`^[+-]?\d+$`),
+	"nonNegativeInteger": regexp.MustCompile(`^\+?\d+$`),
+	"positiveInteger":    regexp.MustCompile(`^\+?0*[1-9]\d*$`),
+	"date":               regexp.MustCompile(`^\d{4}-\d{2}-\d{2}(Z|[+-]\d{2}:\d{2})?$`),
+	"time":               regexp.MustCompile(`^\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:\d{2})?$`),
+	"dateTime":           regexp.MustCompile(`^\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:\d{2})?$`),
+}
+
+// Validate checks document read from r against the schema.
+// Returns nil or Errors
+func (s *Schema) Validate(r io.Reader) error {
+	buf, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	root, err := decode(buf)
+	if err != nil {
+		return Errors{err.(*Error)}
+	}
+	return s.validate(root)
+}
+
+func (s *Schema) validate(root *node) error {
+	v := &validator{schema: s}
+	e, ok := s.elements[root.name]
+	if !ok {
+		v.fail(root, "unexpected root element %s", root.name)
+	} else {
+		v.element(root, e)
+	}
+	if len(v.errors) > 0 {
+		return v.errors
+	}
+	return nil
+}
+
+// Validate checks document read from r against bundled schema
+// of its ВерсияСхемы. Returns nil or Errors
+func Validate(r io.Reader) error {
+	buf, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	root, err := decode(buf)
+	if err != nil {
+		return Errors{err.(*Error)}
+	}
+	version, _ := root.attr("ВерсияСхемы")
+	s, err := ForVersion(version)
+	if err != nil {
+		return Errors{&Error{Line: root.line, Column: root.column, Message: err.Error()}}
+	}
+	return s.validate(root)
+}
+
+// ValidateFile checks file against bundled schema of its ВерсияСхемы
+func ValidateFile(fname string) error {
+	f, err := os.Open(fname)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return Validate(f)
+}
+
+// ValidateBytes checks buf against bundled schema of its ВерсияСхемы
+func ValidateBytes(buf []byte) error {
+	return Validate(bytes.NewReader(buf))
+}
+
+// ValidateDocument checks x as written by xml.Write
+func ValidateDocument(x *cml.КоммерческаяИнформация) error {
+	var buf bytes.Buffer
+	if err := cml.Write(x, &buf); err != nil {
+		return err
+	}
+	return Validate(&buf)
+}
+
+func (v *validator) fail(n *node, format string, args ...interface{}) {
+	v.errors = append(v.errors, &Error{
+		Line:    n.line,
+		Column:  n.column,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+func (v *validator) element(n *node, e *element) {
+	if e.simple != nil {
+		v.simpleContent(n, e.simple, "")
+		return
+	}
+	if e.complex != nil {
+		v.complexContent(n, e.complex)
+		return
+	}
+	if e.typeName == xsdAny {
+		return
+	}
+	if t, ok := v.schema.types[e.typeName]; ok {
+		v.complexContent(n, t)
+		return
+	}
+	v.simpleContent(n, nil, e.typeName)
+}
+
+func (v *validator) simpleContent(n *node, t *simpleType, typeName string) {
+	if len(n.children) > 0 {
+		v.fail(n.children[0], "unexpected element %s in %s", n.children[0].name, n.name)
+	}
+	if len(n.attrs) > 0 {
+		v.attributes(n, nil)
+	}
+	if err := v.check(n.text, t, typeName); err != "" {
+		v.fail(n, "%s: %s", n.name, err)
+	}
+}
+
+// check returns description of error or empty string if value is valid
+func (v *validator) check(value string, t *simpleType, typeName string) string {
+	for depth := 0; depth < 32; depth++ {
+		if t == nil {
+			if re, ok := builtin[typeName]; ok {
+				if typeName != "string" && typeName != "anySimpleType" {
+					value = strings.TrimSpace(value)
+				}
+				if !re.MatchString(value) {
+					return fmt.Sprintf("%q is not a valid %s", value, typeName)
+				}
+				return ""
+			}
+			st, ok := v.schema.simple[typeName]
+			if !ok {
+				return "unknown type " + typeName
+			}
+			t = st
+		}
+		if len(t.enum) > 0 {
+			found := false
+			for _, e := range t.enum {
+				if strings.TrimSpace(value) == e {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return fmt.Sprintf("%q is not one of %s", value, strings.Join(t.enum, ", "))
+			}
+		}
+		t, typeName = nil, t.base
+	}
+	return "type derivation is too deep"
+}
+
+func (v *validator) attributes(n *node, t *complexType) {
+	var decl []*attribute
+	if t != nil {
+		decl = t.attrs
+	}
+	seen := make(map[string]bool)
+	for _, a := range n.attrs {
+		if a.Name.Space == "xmlns" || (a.Name.Space == "" && a.Name.Local == "xmlns") ||
+			strings.HasSuffix(a.Name.Space, "XMLSchema-instance") || a.Name.Space == "xsi" {
+			continue
+		}
+		var d *attribute
+		for _, da := range decl {
+			if da.name == a.Name.Local {
+				d = da
+				break
+			}
+		}
+		if d == nil {
+			v.fail(n, "unexpected attribute %s in %s", a.Name.Local, n.name)
+			continue
+		}
+		seen[d.name] = true
+		if err := v.check(a.Value, d.simple, d.typeName); err != "" {
+			v.fail(n, "attribute %s: %s", d.name, err)
+		}
+	}
+	for _, d := range decl {
+		if d.required && !seen[d.name] {
+			v.fail(n, "missing required attribute %s in %s", d.name, n.name)
+		}
+	}
+}
+
+func (v *validator) complexContent(n *node, t *complexType) {
+	v.attributes(n, t)
+
+	if t.text != nil {
+		v.simpleContent(&node{
+			name: n.name, children: n.children, text: n.text,
+			line: n.line, column: n.column,
+		}, nil, t.text.base)
+		return
+	}
+
+	if !t.mixed && strings.TrimSpace(n.text) != "" {
+		v.fail(n, "unexpected text in %s", n.name)
+	}
+
+	if t.content == nil {
+		if len(n.children) > 0 {
+			v.fail(n.children[0], "unexpected element %s in %s", n.children[0].name, n.name)
+		}
+		return
+	}
+
+	bind := make([]*element, len(n.children))
+	v.expectPos, v.expectName = -1, nil
+	pos, ok := v.match(t.content, n.children, bind, 0)
+
+	if ok && pos == len(n.children) {
+		for i := range n.children {
+			v.element(n.children[i], bind[i])
+		}
+		return
+	}
+
+	// report where the matcher went furthest
+	expect := ""
+	if v.expectPos >= pos && len(v.expectName) > 0 {
+		pos = v.expectPos
+		expect = ", expected " + strings.Join(v.expectName, " or ")
+	}
+	for i := 0; i < pos; i++ {
+		if bind[i] != nil {
+			v.element(n.children[i], bind[i])
+		}
+	}
+	if pos < len(n.children) {
+		c := n.children[pos]
+		v.fail(c, "unexpected element %s in %s%s", c.name, n.name, expect)
+	} else {
+		v.fail(n, "element %s is incomplete%s", n.name, expect)
+	}
+}
+
+func (v *validator) expect(pos int, name string) {
+	if pos > v.expectPos {
+		v.expectPos, v.expectName = pos, nil
+	}
+	if pos == v.expectPos {
+		for _, n := range v.expectName {
+			if n == name {
+				return
+			}
+		}
+		v.expectName = append(v.expectName, name)
+	}
+}
+
+// match greedily consumes children from pos by particle p with its occurrences
+func (v *validator) match(p *particle, ch []*node, bind []*element, pos int) (int, bool) {
+	n := 0
+	for p.max < 0 || n < p.max {
+		next, ok := v.matchOnce(p, ch, bind, pos)
+		if !ok {
+			break
+		}
+		n++
+		if next == pos {
+			// empty match satisfies any number of required occurrences
+			if n < p.min {
+				n = p.min
+			}
+			break
+		}
+		pos = next
+	}
+	return pos, n >= p.min
+}
+
+func (v *validator) matchOnce(p *particle, ch []*node, bind []*element, pos int) (int, bool) {
+	switch p.kind {
+	case "element":
+		if pos < len(ch) && ch[pos].name == p.element.name {
+			bind[pos] = p.element
+			return pos + 1, true
+		}
+		v.expect(pos, p.element.name)
+		return pos, false
+
+	case "sequence":
+		start := pos
+		for _, item := range p.items {
+			var ok bool
+			if pos, ok = v.match(item, ch, bind, pos); !ok {
+				return start, false
+			}
+		}
+		return pos, true
+
+	case "choice":
+		empty := false
+		for _, item := range p.items {
+			next, ok := v.match(item, ch, bind, pos)
+			if ok && next > pos {
+				return next, true
+			}
+			empty = empty || ok
+		}
+		return pos, empty
+
+	case "all":
+		count := make([]int, len(p.items))
+	next:
+		for pos < len(ch) {
+			for i, item := range p.items {
+				if ch[pos].name == item.element.name && (item.max < 0 || count[i] < item.max) {
+					bind[pos] = item.element
+					count[i]++
+					pos++
+					continue next
+				}
+			}
+			break
+		}
+		ok := true
+		for i, item := range p.items {
+			if count[i] < item.min {
+				v.expect(pos, item.element.name)
+				ok = false
+			}
+		}
+		return pos, ok
+	}
+	return pos, false
+}
@@ -0,0 +1,386 @@
+// Package xsd validates CommerceML documents against bundled XML Schemas.
+//
+// Only the subset of XML Schema used by the bundled CommerceML schemas is
+// supported: element, complexType (sequence, choice, all, simpleContent,
+// complexContent extension), simpleType restriction with enumeration,
+// attribute. Namespaces are ignored, elements are matched by local name.
+package xsd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"strings"
+)
+
+type (
+	// Schema is a parsed XML Schema
+	Schema struct {
+		elements map[string]*element
+		types    map[string]*complexType
+		simple   map[string]*simpleType
+	}
+
+	element struct {
+		name     string
+		typeName string
+		complex  *complexType
+		simple   *simpleType
+		line     int
+	}
+
+	particle struct {
+		kind     string // element, sequence, choice, all
+		element  *element
+		items    []*particle
+		min, max int // max < 0 is unbounded
+	}
+
+	attribute struct {
+		name     string
+		typeName string
+		simple   *simpleType
+		required bool
+	}
+
+	complexType struct {
+		content *particle
+		attrs   []*attribute
+		base    string      // complexContent extension base
+		text    *simpleType // simpleContent
+		mixed   bool
+	}
+
+	simpleType struct {
+		base string
+		enum []string
+	}
+)
+
+const xsdAny = "anyType"
+
+// Parse reads XML Schema from r
+func Parse(r io.Reader) (*Schema, error) {
+	buf, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	root, err := decode(buf)
+	if err != nil {
+		return nil, err
+	}
+	if root.name != "schema" {
+		return nil, schemaError(root, "root element is not schema")
+	}
+
+	s := &Schema{
+		elements: make(map[string]*element),
+		types:    make(map[string]*complexType),
+		simple:   make(map[string]*simpleType),
+	}
+	for _, n := range root.children {
+		name, _ := n.attr("name")
+		switch n.name {
+		case "element":
+			p, err := s.parseElement(n)
+			if err != nil {
+				return nil, err
+			}
+			s.elements[p.element.name] = p.element
+		case "complexType":
+			t, err := s.parseComplexType(n)
+			if err != nil {
+				return nil, err
+			}
+			s.types[name] = t
+		case "simpleType":
+			t, err := s.parseSimpleType(n)
+			if err != nil {
+				return nil, err
+			}
+			s.simple[name] = t
+		case "annotation":
+		default:
+			return nil, schemaError(n, "unsupported %s", n.name)
+		}
+	}
+
+	if err := s.resolve(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// MustParse parses schema from string or panic
+func MustParse(schema string) *Schema {
+	s, err := Parse(bytes.NewBufferString(schema))
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+func schemaError(n *node, format string, args ...interface{}) error {
+	return &Error{Line: n.line, Column: n.column, Message: "schema: " + fmt.Sprintf(format, args...)}
+}
+
+// local strips namespace prefix of type name
+func local(name string) string {
+	if i := strings.IndexByte(name, ':'); i >= 0 {
+		return name[i+1:]
+	}
+	return name
+}
+
+func occurs(n *node) (int, int, error) {
+	min, max := 1, 1
+	if v, ok := n.attr("minOccurs"); ok {
+		i, err := strconv.Atoi(v)
+		if err != nil {
+			return 0, 0, schemaError(n, "bad minOccurs %q", v)
+		}
+		min = i
+	}
+	if v, ok := n.attr("maxOccurs"); ok {
+		if v == "unbounded" {
+			max = -1
+		} else {
+			i, err := strconv.Atoi(v)
+			if err != nil {
+				return 0, 0, schemaError(n, "bad maxOccurs %q", v)
+			}
+			max = i
+		}
+	}
+	return min, max, nil
+}
+
+func (s *Schema) parseElement(n *node) (*particle, error) {
+	min, max, err := occurs(n)
+	if err != nil {
+		return nil, err
+	}
+	name, ok := n.attr("name")
+	if !ok {
+		return nil, schemaError(n, "element without name")
+	}
+	e := &element{name: name, line: n.line}
+	if t, ok := n.attr("type"); ok {
+		e.typeName = local(t)
+	}
+	for _, c := range n.children {
+		switch c.name {
+		case "complexType":
+			if e.complex, err = s.parseComplexType(c); err != nil {
+				return nil, err
+			}
+		case "simpleType":
+			if e.simple, err = s.parseSimpleType(c); err != nil {
+				return nil, err
+			}
+		case "annotation":
+		default:
+			return nil, schemaError(c, "unsupported %s in element", c.name)
+		}
+	}
+	if e.typeName == "" && e.complex == nil && e.simple == nil {
+		e.typeName = xsdAny
+	}
+	return &particle{kind: "element", element: e, min: min, max: max}, nil
+}
+
+func (s *Schema) parseParticle(n *node) (*particle, error) {
+	if n.name == "element" {
+		return s.parseElement(n)
+	}
+	min, max, err := occurs(n)
+	if err != nil {
+		return nil, err
+	}
+	p := &particle{kind: n.name, min: min, max: max}
+	for _, c := range n.children {
+		if c.name == "annotation" {
+			continue
+		}
+		if p.kind == "all" && c.name != "element" {
+			return nil, schemaError(c, "only elements allowed in all")
+		}
+		item, err := s.parseParticle(c)
+		if err != nil {
+			return nil, err
+		}
+		p.items = append(p.items, item)
+	}
+	return p, nil
+}
+
+func (s *Schema) parseAttribute(n *node) (*attribute, error) {
+	name, ok := n.attr("name")
+	if !ok {
+		return nil, schemaError(n, "attribute without name")
+	}
+	a := &attribute{name: name, typeName: "string"}
+	if t, ok := n.attr("type"); ok {
+		a.typeName = local(t)
+	}
+	if use, _ := n.attr("use"); use == "required" {
+		a.required = true
+	}
+	for _, c := range n.children {
+		if c.name == "simpleType" {
+			t, err := s.parseSimpleType(c)
+			if err != nil {
+				return nil, err
+			}
+			a.simple = t
+		}
+	}
+	return a, nil
+}
+
+func (s *Schema) parseComplexType(n *node) (*complexType, error) {
+	t := new(complexType)
+	if mixed, _ := n.attr("mixed"); mixed == "true" {
+		t.mixed = true
+	}
+	for _, c := range n.children {
+		switch c.name {
+		case "sequence", "choice", "all":
+			p, err := s.parseParticle(c)
+			if err != nil {
+				return nil, err
+			}
+			t.content = p
+		case "attribute":
+			a, err := s.parseAttribute(c)
+			if err != nil {
+				return nil, err
+			}
+			t.attrs = append(t.attrs, a)
+		case "simpleContent", "complexContent":
+			if len(c.children) != 1 || c.children[0].name != "extension" {
+				return nil, schemaError(c, "only extension is supported in %s", c.name)
+			}
+			ext := c.children[0]
+			base, _ := ext.attr("base")
+			sub, err := s.parseComplexType(ext)
+			if err != nil {
+				return nil, err
+			}
+			t.content, t.attrs = sub.content, append(t.attrs, sub.attrs...)
+			if c.name == "simpleContent" {
+				t.text = &simpleType{base: local(base)}
+			} else {
+				t.base = local(base)
+			}
+		case "annotation":
+		default:
+			return nil, schemaError(c, "unsupported %s in complexType", c.name)
+		}
+	}
+	return t, nil
+}
+
+func (s *Schema) parseSimpleType(n *node) (*simpleType, error) {
+	for _, c := range n.children {
+		if c.name != "restriction" {
+			continue
+		}
+		base, _ := c.attr("base")
+		t := &simpleType{base: local(base)}
+		for _, f := range c.children {
+			if f.name == "enumeration" {
+				v, _ := f.attr("value")
+				t.enum = append(t.enum, v)
+			}
+		}
+		return t, nil
+	}
+	return nil, schemaError(n, "simpleType without restriction")
+}
+
+// resolve checks type references and applies complexContent extensions
+func (s *Schema) resolve() error {
+	var walk func(p *particle) error
+	check := func(e *element) error {
+		if e.complex != nil {
+			if err := s.extend(e.complex, 0); err != nil {
+				return err
+			}
+			if e.complex.content != nil {
+				return walk(e.complex.content)
+			}
+			return nil
+		}
+		if e.simple != nil || e.typeName == xsdAny || builtin[e.typeName] != nil {
+			return nil
+		}
+		if _, ok := s.types[e.typeName]; ok {
+			return nil
+		}
+		if _, ok := s.simple[e.typeName]; ok {
+			return nil
+		}
+		return &Error{Line: e.line, Column: 1, Message: "schema: unknown type " + e.typeName}
+	}
+	walk = func(p *particle) error {
+		if p.kind == "element" {
+			return check(p.element)
+		}
+		for _, item := range p.items {
+			if err := walk(item); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	for _, t := range s.types {
+		if err := s.extend(t, 0); err != nil {
+			return err
+		}
+	}
+	for _, t := range s.types {
+		if t.content != nil {
+			if err := walk(t.content); err != nil {
+				return err
+			}
+		}
+	}
+	for _, e := range s.elements {
+		if err := check(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// extend prepends content and attributes of base type
+func (s *Schema) extend(t *complexType, depth int) error {
+	if t.base == "" {
+		return nil
+	}
+	if depth > 32 {
+		return fmt.Errorf("schema: recursive extension of %s", t.base)
+	}
+	base, ok := s.types[t.base]
+	if !ok {
+		return fmt.Errorf("schema: unknown base type %s", t.base)
+	}
+	if err := s.extend(base, depth+1); err != nil {
+		return err
+	}
+	t.base = ""
+	t.attrs = append(append([]*attribute{}, base.attrs...), t.attrs...)
+	switch {
+	case base.content == nil:
+	case t.content == nil:
+		t.content = base.content
+	default:
+		t.content = &particle{kind: "sequence", min: 1, max: 1,
+			items: []*particle{base.content, t.content}}
+	}
+	return nil
+}
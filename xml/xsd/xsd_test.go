@@ -0,0 +1,199 @@
+package xsd
+
+import (
+	"bytes"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	cml "github.com/sevkin/go-cml/xml"
+)
+
+const offers = `<?xml version="1.0" encoding="UTF-8"?>
+<КоммерческаяИнформация xmlns="urn:1C.ru:commerceml_2" ВерсияСхемы="2.04" ДатаФормирования="2019-06-20T12:00:00">
+  <ПакетПредложений СодержитТолькоИзменения="false">
+    <Ид>pack</Ид>
+    <Наименование>Пакет предложений</Наименование>
+    <ИдКаталога>cat</ИдКаталога>
+    <ИдКлассификатора>cls</ИдКлассификатора>
+    <Владелец>
+      <Ид>owner</Ид>
+      <Наименование>ООО Ромашка</Наименование>
+    </Владелец>
+    <ТипыЦен>
+      <ТипЦены>
+        <Ид>retail</Ид>
+        <Наименование>Розничная</Наименование>
+        <Валюта>RUB</Валюта>
+        <Налог>
+          <Наименование>НДС</Наименование>
+          <УчтеноВСумме>true</УчтеноВСумме>
+        </Налог>
+      </ТипЦены>
+    </ТипыЦен>
+    <Предложения>
+      <Предложение>
+        <Ид>p1</Ид>
+        <Наименование>Товар 1</Наименование>
+        <БазоваяЕдиница Код="796" НаименованиеПолное="Штука">шт</БазоваяЕдиница>
+        <Цены>
+          <Цена>
+            <ИдТипаЦены>retail</ИдТипаЦены>
+            <ЦенаЗаЕдиницу>%s</ЦенаЗаЕдиницу>
+          </Цена>
+        </Цены>
+        <Количество>5</Количество>
+        <Склад ИдСклада="w1" КоличествоНаСкладе="5"/>
+      </Предложение>
+    </Предложения>
+  </ПакетПредложений>
+</КоммерческаяИнформация>
+`
+
+func offersWith(price string) *bytes.Buffer {
+	return bytes.NewBufferString(strings.Replace(offers, "%s", price, 1))
+}
+
+func TestBundledSchemasParse(t *testing.T) {
+	for _, v := range Versions() {
+		s, err := ForVersion(v)
+		assert.Nil(t, err, v)
+		assert.NotNil(t, s, v)
+	}
+
+	_, err := ForVersion("1.0")
+	assert.NotNil(t, err)
+}
+
+func TestValidateSuccess(t *testing.T) {
+	assert.Nil(t, Validate(offersWith("100.50")))
+}
+
+func TestValidateBadValue(t *testing.T) {
+	err := Validate(offersWith("сто"))
+	assert.NotNil(t, err)
+
+	errs, ok := err.(Errors)
+	assert.True(t, ok)
+	assert.Equal(t, 1, len(errs))
+	assert.Equal(t, 31, errs[0].Line)
+	assert.Equal(t, 13, errs[0].Column)
+	assert.Contains(t, errs[0].Message, "ЦенаЗаЕдиницу")
+}
+
+func TestValidateUnexpectedElement(t *testing.T) {
+	doc := `<КоммерческаяИнформация ВерсияСхемы="2.05" ДатаФормирования="2019-06-20T12:00:00">
+<Каталог>
+  <Ид>cat</Ид>
+  <Наименование>Каталог</Наименование>
+</Каталог>
+</КоммерческаяИнформация>`
+
+	errs, ok := Validate(bytes.NewBufferString(doc)).(Errors)
+	assert.True(t, ok)
+	assert.Equal(t, 1, len(errs))
+	assert.Equal(t, 4, errs[0].Line)
+	assert.Equal(t, 3, errs[0].Column)
+	assert.Contains(t, errs[0].Message, "expected ИдКлассификатора")
+}
+
+func TestValidateMissingElementAndAttribute(t *testing.T) {
+	doc := `<КоммерческаяИнформация ВерсияСхемы="2.05">
+<Классификатор>
+  <Ид>cls</Ид>
+  <Наименование>Классификатор</Наименование>
+</Классификатор>
+</КоммерческаяИнформация>`
+
+	errs, ok := Validate(bytes.NewBufferString(doc)).(Errors)
+	assert.True(t, ok)
+	assert.Equal(t, 2, len(errs))
+	assert.Contains(t, errs[0].Message, "ДатаФормирования")
+	assert.Equal(t, 2, errs[1].Line)
+	assert.Contains(t, errs[1].Message, "expected Владелец")
+}
+
+func TestValidateUnsupportedVersion(t *testing.T) {
+	err := Validate(bytes.NewBufferString(`<КоммерческаяИнформация ВерсияСхемы="1.0"/>`))
+	assert.NotNil(t, err)
+}
+
+func TestValidateCP1251(t *testing.T) {
+	assert.Nil(t, ValidateFile("../testdata/offers1251.xml"))
+
+	err := Validate(bytes.NewBufferString(`<?xml version="1.0" encoding="koi9"?><КоммерческаяИнформация/>`))
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), `unsupported encoding "koi9"`)
+}
+
+func TestValidateCP1251Position(t *testing.T) {
+	utf, err := ioutil.ReadFile("../testdata/offers.xml")
+	assert.Nil(t, err)
+	cp, err := ioutil.ReadFile("../testdata/offers1251.xml")
+	assert.Nil(t, err)
+
+	want, ok := ValidateBytes(bytes.Replace(utf, []byte("2100"), []byte("n/a"), 1)).(Errors)
+	assert.True(t, ok)
+	assert.Equal(t, 1, len(want))
+	assert.Equal(t, 73, want[0].Line)
+	got, ok := ValidateBytes(bytes.Replace(cp, []byte("2100"), []byte("n/a"), 1)).(Errors)
+	assert.True(t, ok)
+	assert.Equal(t, want, got)
+}
+
+func TestValidateMalformed(t *testing.T) {
+	errs, ok := Validate(bytes.NewBufferString("<a>\n<b></a>")).(Errors)
+	assert.True(t, ok)
+	assert.Equal(t, 1, len(errs))
+	assert.Equal(t, 2, errs[0].Line)
+	assert.Equal(t, 8, errs[0].Column)
+}
+
+func TestValidateDocument(t *testing.T) {
+	x, err := cml.Read(offersWith("1"))
+	assert.Nil(t, err)
+	assert.Nil(t, ValidateDocument(x))
+
+	x.ПакетПредложений.Предложения[0].Цены[0].ЦенаЗаЕдиницу = ""
+	assert.NotNil(t, ValidateDocument(x))
+}
+
+func TestSchemaChoiceAndAll(t *testing.T) {
+	s := MustParse(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:element name="r">
+    <xs:complexType>
+      <xs:sequence>
+        <xs:choice maxOccurs="unbounded">
+          <xs:element name="a"/>
+          <xs:element name="b" type="xs:integer"/>
+        </xs:choice>
+        <xs:element name="c" minOccurs="0">
+          <xs:complexType>
+            <xs:all>
+              <xs:element name="x"/>
+              <xs:element name="y" minOccurs="0"/>
+            </xs:all>
+          </xs:complexType>
+        </xs:element>
+      </xs:sequence>
+    </xs:complexType>
+  </xs:element>
+</xs:schema>`)
+
+	assert.Nil(t, s.Validate(bytes.NewBufferString(`<r><a/><b>1</b><a/><c><y/><x/></c></r>`)))
+	assert.NotNil(t, s.Validate(bytes.NewBufferString(`<r><b>x</b></r>`)))
+	assert.NotNil(t, s.Validate(bytes.NewBufferString(`<r><a/><c><y/></c></r>`)))
+	assert.NotNil(t, s.Validate(bytes.NewBufferString(`<r><c><x/></c></r>`)))
+}
+
+func TestValidateExports(t *testing.T) {
+	for _, fname := range []string{"../testdata/import.xml", "../testdata/offers.xml"} {
+		assert.Nil(t, ValidateFile(fname), fname)
+
+		x, err := cml.ReadFile(fname)
+		assert.Nil(t, err)
+		assert.Nil(t, ValidateDocument(x), fname)
+	}
+}
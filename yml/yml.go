@@ -0,0 +1,263 @@
+// Package yml converts CommerceML catalog to Yandex Market Language (yml_catalog) feed
+// https://yandex.ru/support/partnermarket/export/yml.html
+package yml
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/xml"
+	"hash/fnv"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/sevkin/go-cml/catalog"
+	"github.com/sevkin/go-cml/currency"
+)
+
+type (
+	// Catalog is the root element of YML feed
+	Catalog struct {
+		XMLName xml.Name `xml:"yml_catalog"`
+		Date    string   `xml:"date,attr"`
+		Shop    Shop     `xml:"shop"`
+		// Skipped are Ид of offers not exported with reason
+		Skipped []string `xml:"-"`
+	}
+
+	// Shop describes seller and its goods
+	Shop struct {
+		Name       string     `xml:"name"`
+		Company    string     `xml:"company"`
+		URL        string     `xml:"url"`
+		Currencies []Currency `xml:"currencies>currency"`
+		Categories []Category `xml:"categories>category"`
+		Offers     []Offer    `xml:"offers>offer"`
+	}
+
+	// Currency of prices
+	Currency struct {
+		ID   string `xml:"id,attr"`
+		Rate string `xml:"rate,attr"`
+	}
+
+	// Category is the group of classifier
+	Category struct {
+		ID       int    `xml:"id,attr"`
+		ParentID int    `xml:"parentId,attr,omitempty"`
+		Name     string `xml:",chardata"`
+	}
+
+	// Offer is the product or its variant
+	Offer struct {
+		ID          string   `xml:"id,attr"`
+		GroupID     string   `xml:"group_id,attr,omitempty"`
+		Available   bool     `xml:"available,attr"`
+		URL         string   `xml:"url,omitempty"`
+		Price       string   `xml:"price"`
+		CurrencyID  string   `xml:"currencyId"`
+		CategoryID  int      `xml:"categoryId"`
+		Picture     []string `xml:"picture,omitempty"`
+		Name        string   `xml:"name"`
+		Vendor      string   `xml:"vendor,omitempty"`
+		VendorCode  string   `xml:"vendorCode,omitempty"`
+		Description string   `xml:"description,omitempty"`
+		Barcode     string   `xml:"barcode,omitempty"`
+		Param       []Param  `xml:"param,omitempty"`
+	}
+
+	// Param is the characteristic of offer
+	Param struct {
+		Name  string `xml:"name,attr"`
+		Value string `xml:",chardata"`
+	}
+
+	// Options of conversion
+	Options struct {
+		Shop    string
+		Company string
+		URL     string
+		// PriceType is Ид or Наименование of ТипЦены, the first one if empty
+		PriceType string
+		// Warehouses are Ид or Наименование of Склад to sum stock on,
+		// Quantity of offer (Количество or all stock) is used if empty
+		Warehouses []string
+		// ImageBase is prepended to Картинка path
+		ImageBase string
+		// OfferURL is text/template of offer url executed on *catalog.Offer
+		OfferURL string
+		// Date of feed, now if zero
+		Date time.Time
+		// Currency is ISO code of base currency, Валюта of price type if empty
+		Currency string
+		// Rates of other currencies: units of base currency per one unit.
+		// Offers priced in currency without rate are skipped
+		Rates map[string]float64
+	}
+)
+
+var idRe = regexp.MustCompile(`^[0-9A-Za-z]{1,20}$`)
+
+// offerID returns Ид if it suits YML (up to 20 latin letters and digits) or its hash
+func offerID(id string) string {
+	if idRe.MatchString(id) {
+		return id
+	}
+	sum := sha1.Sum([]byte(id))
+	return hex.EncodeToString(sum[:])[:20]
+}
+
+// maxNumericID is the largest id of category and group_id of offer
+// allowed by YML (9 digits)
+const maxNumericID = 999999999
+
+// numericID returns id of category or group_id of offers derived from Ид
+// of group or product, so adding groups does not renumber the others
+func numericID(id string) int {
+	h := fnv.New32a()
+	h.Write([]byte(id))
+	return int(h.Sum32()%maxNumericID) + 1
+}
+
+// Convert returns feed of c
+func Convert(c *catalog.Catalog, opt Options) (*Catalog, error) {
+	sel, err := c.Select(opt.PriceType, opt.Warehouses)
+	if err != nil {
+		return nil, err
+	}
+
+	var offerURL *template.Template
+	if len(opt.OfferURL) > 0 {
+		t, err := template.New("url").Parse(opt.OfferURL)
+		if err != nil {
+			return nil, err
+		}
+		offerURL = t
+	}
+
+	date := opt.Date
+	if date.IsZero() {
+		date = time.Now()
+	}
+
+	y := &Catalog{
+		Date: date.Format("2006-01-02T15:04:05-07:00"),
+		Shop: Shop{Name: opt.Shop, Company: opt.Company, URL: opt.URL},
+	}
+
+	categories := make(map[*catalog.Group]int)
+	used := make(map[int]bool)
+	for _, g := range c.AllGroups() {
+		id := numericID(g.Ид)
+		// collision of hashes moves the later group to the next free id
+		for used[id] {
+			id = id%maxNumericID + 1
+		}
+		used[id] = true
+		categories[g] = id
+		y.Shop.Categories = append(y.Shop.Categories, Category{
+			ID:       id,
+			ParentID: categories[g.Parent],
+			Name:     g.Наименование,
+		})
+	}
+
+	base := currency.Code(opt.Currency)
+	if len(base) == 0 {
+		base = sel.Currency(nil)
+	}
+	y.Shop.Currencies = append(y.Shop.Currencies, Currency{ID: base, Rate: "1"})
+	currencies := map[string]bool{base: true}
+	for _, o := range c.Offers() {
+		p := o.Product
+		switch {
+		case p == nil:
+			y.Skipped = append(y.Skipped, o.Ид+": unknown product")
+			continue
+		case p.Deleted():
+			y.Skipped = append(y.Skipped, o.Ид+": product deleted")
+			continue
+		case len(p.Groups) == 0:
+			y.Skipped = append(y.Skipped, o.Ид+": product without group")
+			continue
+		}
+		price := sel.Price(o)
+		if price == nil {
+			y.Skipped = append(y.Skipped, o.Ид+": no price")
+			continue
+		}
+
+		currency := sel.Currency(price)
+		if !currencies[currency] {
+			rate, found := opt.Rates[currency]
+			if !found || rate <= 0 {
+				y.Skipped = append(y.Skipped, o.Ид+": no rate of "+currency)
+				continue
+			}
+			currencies[currency] = true
+			y.Shop.Currencies = append(y.Shop.Currencies,
+				Currency{ID: currency, Rate: strconv.FormatFloat(rate, 'f', -1, 64)})
+		}
+
+		offer := Offer{
+			ID:          offerID(o.Ид),
+			Available:   sel.Available(o),
+			Price:       strings.Replace(price.ЦенаЗаЕдиницу, ",", ".", 1),
+			CurrencyID:  currency,
+			CategoryID:  categories[p.Groups[0]],
+			Name:        p.Наименование,
+			VendorCode:  p.Артикул,
+			Description: p.Описание,
+			Barcode:     p.Штрихкод,
+		}
+		if p.Изготовитель != nil {
+			offer.Vendor = p.Изготовитель.Наименование
+		}
+		if o.Variant() {
+			offer.GroupID = strconv.Itoa(numericID(p.Ид))
+			if len(o.Наименование) > 0 {
+				offer.Name = o.Наименование
+			}
+			if len(o.Артикул) > 0 {
+				offer.VendorCode = o.Артикул
+			}
+			if o.ХарактеристикиТовара != nil {
+				for _, ch := range *o.ХарактеристикиТовара {
+					offer.Param = append(offer.Param, Param{Name: ch.Наименование, Value: ch.Значение})
+				}
+			}
+		}
+		for _, path := range p.Картинка {
+			offer.Picture = append(offer.Picture, opt.ImageBase+path)
+		}
+		if offerURL != nil {
+			var url bytes.Buffer
+			if err := offerURL.Execute(&url, o); err != nil {
+				return nil, err
+			}
+			offer.URL = url.String()
+		}
+
+		y.Shop.Offers = append(y.Shop.Offers, offer)
+	}
+
+	return y, nil
+}
+
+// Write writes y as XML document to w
+func Write(y *Catalog, w io.Writer) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(y); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,136 @@
+package yml
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sevkin/go-cml/catalog"
+	cml "github.com/sevkin/go-cml/xml"
+)
+
+func load(t *testing.T) *catalog.Catalog {
+	c, err := catalog.LoadFiles("../xml/testdata/import.xml", "../xml/testdata/offers.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestOfferID(t *testing.T) {
+	assert.Equal(t, "abc123", offerID("abc123"))
+	id := offerID("c5e4a1b0-3d2e-11e9-80d4-0cc47a7c2f11#8a9b0c1d-6e7f-11e9-80d4-0cc47a7c2f11")
+	assert.Equal(t, 20, len(id))
+	assert.NotEqual(t, id, offerID("c5e4a1b0-3d2e-11e9-80d4-0cc47a7c2f11#8a9b0c1e-6e7f-11e9-80d4-0cc47a7c2f11"))
+}
+
+func TestConvert(t *testing.T) {
+	c := load(t)
+	y, err := Convert(c, Options{
+		Shop:      "Shop",
+		PriceType: "Розничная",
+		ImageBase: "https://example.com/",
+		OfferURL:  "https://example.com/p/{{.Product.Артикул}}",
+		Date:      time.Date(2019, 7, 1, 10, 0, 0, 0, time.UTC),
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "2019-07-01T10:00:00+00:00", y.Date)
+
+	assert.Equal(t, len(c.AllGroups()), len(y.Shop.Categories))
+	ids := make(map[int]bool)
+	for _, cat := range y.Shop.Categories {
+		ids[cat.ID] = true
+		assert.True(t, cat.ID > 0 && cat.ID <= maxNumericID)
+		if cat.ParentID != 0 {
+			assert.True(t, ids[cat.ParentID])
+		}
+	}
+	assert.Equal(t, numericID("9e1f0a11-2b3c-11e9-80d4-0cc47a7c2f11"), y.Shop.Offers[0].CategoryID)
+	assert.Equal(t, []Currency{{ID: "RUB", Rate: "1"}}, y.Shop.Currencies)
+
+	assert.Equal(t, 4, len(y.Shop.Offers))
+	variant := y.Shop.Offers[0]
+	assert.True(t, variant.Available)
+	assert.Equal(t, "2990", variant.Price)
+	assert.Equal(t, "ПЛ-001", variant.VendorCode)
+	assert.Equal(t, strconv.Itoa(numericID("c5e4a1b0-3d2e-11e9-80d4-0cc47a7c2f11")), variant.GroupID)
+	assert.Regexp(t, `^[1-9][0-9]{0,8}$`, variant.GroupID)
+	assert.Equal(t, "https://example.com/p/ПЛ-001", variant.URL)
+	assert.Equal(t, "https://example.com/import_files/c5/c5e4a1b03d2e11e980d40cc47a7c2f11_a1.jpg", variant.Picture[0])
+	assert.NotEmpty(t, variant.Param)
+	assert.False(t, y.Shop.Offers[1].Available)
+	assert.Equal(t, "3450.50", y.Shop.Offers[2].Price)
+
+	var buf bytes.Buffer
+	assert.Nil(t, Write(y, &buf))
+	var back Catalog
+	assert.Nil(t, xml.Unmarshal(buf.Bytes(), &back))
+	assert.Equal(t, y.Date, back.Date)
+	assert.Equal(t, y.Shop, back.Shop)
+}
+
+func TestCategoryStable(t *testing.T) {
+	c := load(t)
+	y, err := Convert(c, Options{})
+	assert.Nil(t, err)
+
+	// new group does not renumber the others
+	c.Add(&cml.КоммерческаяИнформация{Классификатор: &cml.Классификатор{
+		Группы: []cml.Группа{{Ид: "new", Наименование: "Аксессуары"}},
+	}})
+	z, err := Convert(c, Options{})
+	assert.Nil(t, err)
+	assert.Equal(t, len(y.Shop.Categories)+1, len(z.Shop.Categories))
+	ids := make(map[string]int)
+	for _, cat := range z.Shop.Categories {
+		ids[cat.Name] = cat.ID
+	}
+	for _, cat := range y.Shop.Categories {
+		assert.Equal(t, cat.ID, ids[cat.Name], cat.Name)
+	}
+	for idx := range y.Shop.Offers {
+		assert.Equal(t, y.Shop.Offers[idx].CategoryID, z.Shop.Offers[idx].CategoryID)
+	}
+}
+
+func TestConvertCurrencies(t *testing.T) {
+	c := load(t)
+	c.Offers()[0].Цены = []cml.Цена{{ИдТипаЦены: "d1c0a2b3-4e5f-11e9-80d4-0cc47a7c2f11",
+		ЦенаЗаЕдиницу: "40", Валюта: "USD"}}
+
+	y, err := Convert(c, Options{PriceType: "Розничная"})
+	assert.Nil(t, err)
+	assert.Equal(t, []Currency{{ID: "RUB", Rate: "1"}}, y.Shop.Currencies)
+	assert.Equal(t, 3, len(y.Shop.Offers))
+	assert.Contains(t, y.Skipped, c.Offers()[0].Ид+": no rate of USD")
+
+	y, err = Convert(c, Options{PriceType: "Розничная", Rates: map[string]float64{"USD": 63.5}})
+	assert.Nil(t, err)
+	assert.Equal(t, []Currency{{ID: "RUB", Rate: "1"}, {ID: "USD", Rate: "63.5"}}, y.Shop.Currencies)
+	assert.Equal(t, "USD", y.Shop.Offers[0].CurrencyID)
+
+	y, err = Convert(c, Options{PriceType: "Розничная", Currency: "USD", Rates: map[string]float64{"RUB": 0.016}})
+	assert.Nil(t, err)
+	assert.Equal(t, []Currency{{ID: "USD", Rate: "1"}, {ID: "RUB", Rate: "0.016"}}, y.Shop.Currencies)
+}
+
+func TestConvertWarehouses(t *testing.T) {
+	c := load(t)
+	y, err := Convert(c, Options{PriceType: "Оптовая", Warehouses: []string{"Магазин на Ленина"}})
+	assert.Nil(t, err)
+	for _, o := range y.Shop.Offers {
+		assert.Equal(t, o.VendorCode == "ПЛ-001", o.Available, o.VendorCode)
+	}
+	assert.NotEmpty(t, y.Skipped)
+
+	_, err = Convert(c, Options{PriceType: "Закупочная"})
+	assert.NotNil(t, err)
+	_, err = Convert(c, Options{Warehouses: []string{"Склад"}})
+	assert.NotNil(t, err)
+	_, err = Convert(c, Options{OfferURL: "{{"})
+	assert.NotNil(t, err)
+}